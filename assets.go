@@ -0,0 +1,101 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// AssetUrlOptions configures the transformation of asset URLs in templates into module imports.
+// Relative URLs ("./logo.png", "~/logo.png") and aliased URLs ("@/assets/logo.svg") become imports,
+// which are loaded by the loader the build configures for their extension (e.g. api.LoaderFile).
+type AssetUrlOptions struct {
+	Disabled        bool                // Keep all asset URLs as written
+	Tags            map[string][]string // Attributes transformed per tag, nil uses DefaultAssetUrlTags
+	IncludeAbsolute bool                // Also import absolute URLs like "/logo.png", which are served as is by default
+	FileLoader      bool                // Load common image, media and font extensions without a configured loader with the file loader
+}
+
+// DefaultAssetUrlTags returns the attributes whose URLs are transformed by default, the same as Vue's.
+func DefaultAssetUrlTags() map[string][]string {
+	return map[string][]string{
+		"video":  {"src", "poster"},
+		"source": {"src"},
+		"img":    {"src"},
+		"image":  {"xlink:href", "href"},
+		"use":    {"xlink:href", "href"},
+	}
+}
+
+// defaultAssetLoaders are the extensions loaded with the file loader by AssetUrlOptions.FileLoader
+var defaultAssetLoaders = []string{
+	".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".avif", ".ico",
+	".mp4", ".webm", ".ogg", ".mp3", ".wav",
+	".woff", ".woff2", ".ttf", ".eot",
+}
+
+// transformAssetUrls returns the transformAssetUrls option of the template compiler.
+func (o *AssetUrlOptions) transformAssetUrls() any {
+	if o == nil {
+		return map[string]any{"tags": DefaultAssetUrlTags(), "includeAbsolute": false}
+	}
+	if o.Disabled {
+		return false
+	}
+	tags := o.Tags
+	if tags == nil {
+		tags = DefaultAssetUrlTags()
+	}
+	return map[string]any{"tags": tags, "includeAbsolute": o.IncludeAbsolute}
+}
+
+// applyAssetLoaders loads the asset files imported by templates with the file loader if FileLoader is set,
+// extensions with a loader configured by the build are left unchanged.
+func applyAssetLoaders(opts *Options, buildOptions *api.BuildOptions) {
+	if opts.assetUrls == nil || opts.assetUrls.Disabled || !opts.assetUrls.FileLoader {
+		return
+	}
+	if buildOptions.Loader == nil {
+		buildOptions.Loader = make(map[string]api.Loader)
+	}
+	for _, ext := range defaultAssetLoaders {
+		if _, exists := buildOptions.Loader[ext]; !exists {
+			buildOptions.Loader[ext] = api.LoaderFile
+		}
+	}
+}
+
+// registerAssetResolveHandler resolves the imports of compiled templates through the tsconfig path aliases,
+// so asset URLs like "@/assets/logo.svg" point to the aliased file. Other imports are resolved by esbuild.
+// Templates inlined into <script setup> import their assets from the script module.
+func registerAssetResolveHandler(opts *Options, build *api.PluginBuild) {
+	resolve := func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+		aliasedPath, err := opts.pathAlias.apply(args.Path)
+		if err != nil {
+			return api.OnResolveResult{}, err
+		}
+		if aliasedPath == args.Path {
+			return api.OnResolveResult{}, nil
+		}
+
+		// Resolve the aliased path as if it was imported from a file next to the component
+		result := build.Resolve(aliasedPath, api.ResolveOptions{
+			Importer:   args.Importer,
+			Namespace:  "file",
+			ResolveDir: args.ResolveDir,
+			Kind:       args.Kind,
+		})
+		if len(result.Errors) > 0 {
+			return api.OnResolveResult{Errors: result.Errors}, nil
+		}
+		return api.OnResolveResult{
+			Path:      result.Path,
+			Namespace: result.Namespace,
+			External:  result.External,
+		}, nil
+	}
+	// Relative and absolute imports are never aliased
+	build.OnResolve(api.OnResolveOptions{Filter: `^[^./]`, Namespace: "sfc-template"}, resolve)
+	build.OnResolve(api.OnResolveOptions{Filter: `^[^./]`, Namespace: "sfc-script"}, resolve)
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestTransformAssetUrls(t *testing.T) {
+	var defaults *AssetUrlOptions
+	expected := map[string]any{"tags": DefaultAssetUrlTags(), "includeAbsolute": false}
+	if !reflect.DeepEqual(defaults.transformAssetUrls(), expected) {
+		t.Errorf("Expected the default tags, got %v", defaults.transformAssetUrls())
+	}
+
+	options := &AssetUrlOptions{Tags: map[string][]string{"img": {"src", "data-src"}}, IncludeAbsolute: true}
+	expected = map[string]any{"tags": map[string][]string{"img": {"src", "data-src"}}, "includeAbsolute": true}
+	if !reflect.DeepEqual(options.transformAssetUrls(), expected) {
+		t.Errorf("Expected the configured tags, got %v", options.transformAssetUrls())
+	}
+
+	options = &AssetUrlOptions{Disabled: true}
+	if options.transformAssetUrls() != false {
+		t.Errorf("Expected false when disabled, got %v", options.transformAssetUrls())
+	}
+}
+
+func TestApplyAssetLoaders(t *testing.T) {
+	// The loaders of the build are kept by default
+	buildOptions := &api.BuildOptions{}
+	applyAssetLoaders(newOptions(), buildOptions)
+	if len(buildOptions.Loader) != 0 {
+		t.Errorf("Expected no loaders by default, got %v", buildOptions.Loader)
+	}
+
+	opts := newOptions()
+	WithAssetUrls(AssetUrlOptions{FileLoader: true})(opts)
+	buildOptions = &api.BuildOptions{Loader: map[string]api.Loader{".svg": api.LoaderText}}
+	applyAssetLoaders(opts, buildOptions)
+	if buildOptions.Loader[".png"] != api.LoaderFile || buildOptions.Loader[".woff2"] != api.LoaderFile {
+		t.Errorf("Expected the file loader for assets, got %v", buildOptions.Loader)
+	}
+	if buildOptions.Loader[".svg"] != api.LoaderText {
+		t.Errorf("Expected the configured loader to be kept, got %v", buildOptions.Loader[".svg"])
+	}
+
+	// Builds with disabled asset URLs keep their loaders
+	opts = newOptions()
+	WithAssetUrls(AssetUrlOptions{Disabled: true, FileLoader: true})(opts)
+	buildOptions = &api.BuildOptions{}
+	applyAssetLoaders(opts, buildOptions)
+	if len(buildOptions.Loader) != 0 {
+		t.Errorf("Expected no loaders when disabled, got %v", buildOptions.Loader)
+	}
+}
+
+// Integration tests
+
+func TestVueTemplateAssetUrls(t *testing.T) {
+	tmpDir := t.TempDir()
+	for file, contents := range map[string]string{
+		"src/App.vue":         `<template><img src="./logo.png"><img src="@/assets/logo.svg"></template>`,
+		"src/logo.png":        "png",
+		"src/assets/logo.svg": "<svg></svg>",
+		"src/main.js":         `import App from './App.vue'; console.log(App);`,
+	} {
+		path := filepath.Join(tmpDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", file, err)
+		}
+	}
+
+	var compileOptions map[string]interface{}
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		// The compiled template imports the asset URLs
+		Template: &MockTemplateConfig{Code: `import _imports_0 from './logo.png';
+import _imports_1 from '@/assets/logo.svg';
+export function render() { return [_imports_0, _imports_1]; }`},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				compileOptions = req.Args[3].(map[string]interface{})
+			}
+		},
+	})
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{filepath.Join(tmpDir, "src", "main.js")},
+		Bundle:        true,
+		Write:         false,
+		Outdir:        filepath.Join(tmpDir, "dist"),
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		TsconfigRaw:   `{"compilerOptions":{"paths":{"@/*":["./src/*"]}}}`,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithAssetUrls(AssetUrlOptions{FileLoader: true}))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if transform, _ := compileOptions["transformAssetUrls"].(map[string]any); !reflect.DeepEqual(transform["tags"], DefaultAssetUrlTags()) {
+		t.Errorf("Expected asset URLs to be transformed by default, got %v", compileOptions["transformAssetUrls"])
+	}
+
+	// Both assets are emitted with a content hash and referenced by the bundle
+	var bundle string
+	assets := map[string]string{}
+	for _, file := range result.OutputFiles {
+		name := filepath.Base(file.Path)
+		switch {
+		case name == "main.js":
+			bundle = string(file.Contents)
+		case strings.HasPrefix(name, "logo-"):
+			assets[filepath.Ext(name)] = name
+		}
+	}
+	for _, ext := range []string{".png", ".svg"} {
+		name, ok := assets[ext]
+		if !ok {
+			t.Errorf("Expected a hashed logo%s to be emitted, got %v", ext, assets)
+			continue
+		}
+		if !strings.Contains(bundle, name) {
+			t.Errorf("Expected the bundle to reference %s, got:\n%s", name, bundle)
+		}
+	}
+}
+
+func TestVueInlineTemplateAssetUrls(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"src/App.vue":         `<script setup></script><template><img src="@/assets/logo.svg"></template>`,
+		"src/assets/logo.svg": "<svg></svg>",
+		"src/main.js":         `import App from './App.vue'; console.log(App);`,
+	})
+
+	// The inlined template imports the asset URLs from the script module
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Script: &MockScriptConfig{Content: `import _imports_0 from '@/assets/logo.svg';
+export default { setup() { return () => _imports_0 } }`, Lang: "js", Setup: true},
+		Template: &MockTemplateConfig{Inline: true},
+	})
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{filepath.Join(tmpDir, "src", "main.js")},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		Loader:        map[string]api.Loader{".svg": api.LoaderDataURL},
+		TsconfigRaw:   `{"compilerOptions":{"paths":{"@/*":["./src/*"]}}}`,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if bundle := string(result.OutputFiles[0].Contents); !strings.Contains(bundle, "data:image/svg+xml") {
+		t.Errorf("Expected the aliased asset to be bundled, got:\n%s", bundle)
+	}
+}
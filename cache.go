@@ -0,0 +1,414 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	qjscompiler "github.com/buke/esbuild-plugin-vue-go/engines/quickjs-go"
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/cespare/xxhash"
+)
+
+// Defaults of the persistent compile cache
+const (
+	DefaultCompileCacheMaxSize = 512 << 20           // Maximum total size of the entries in bytes
+	DefaultCompileCacheMaxAge  = 30 * 24 * time.Hour // Entries unused for longer are evicted
+)
+
+// compileCacheFormat is the version of the entry format, part of every key.
+const compileCacheFormat = "1"
+
+// CompileCacheOptions holds configuration options for the persistent compile cache.
+type CompileCacheOptions struct {
+	Dir          string        // Directory storing the cache entries, empty keeps the results in memory only
+	CompilerHash string        // Identifies the compiler bundle run by the JS executor, defaults to the hash of the embedded compiler
+	MaxSize      int64         // Maximum total size of the entries in bytes, defaults to DefaultCompileCacheMaxSize
+	MaxAge       time.Duration // Entries unused for longer are evicted, defaults to DefaultCompileCacheMaxAge
+}
+
+// compileCacheEntry is the content of a cache file.
+type compileCacheEntry struct {
+	Dependencies map[string]string `json:"dependencies"` // Hashes of the files loaded by the compilation
+	Result       json.RawMessage   `json:"result"`       // Result of the JS executor
+}
+
+// compileCache keeps the results of compilations through the JS executor in memory between
+// rebuilds of an esbuild context and, if a directory is configured, on disk so they survive
+// the process. Entries are keyed by the request, which contains the source and all compiler
+// options, and by the compiler bundle. They are only used while the files loaded by the
+// compilation (e.g. Sass partials) are unchanged.
+type compileCache struct {
+	dir          string // Directory of the persistent cache, empty if only the memory is used
+	compilerHash string
+	maxSize      int64
+	maxAge       time.Duration
+	logger       *slog.Logger
+
+	scan sync.Once  // The cache directory is scanned once, on first use
+	mu   sync.Mutex // Guards size
+	size int64      // Total size of the entries on disk
+
+	memoryMu   sync.Mutex
+	memory     map[string]*memoryCacheEntry // Entries of the recent builds by key
+	generation int                          // Number of the running build
+}
+
+// memoryCacheEntry is an entry of the in-memory cache.
+type memoryCacheEntry struct {
+	result     json.RawMessage      // Result of the JS executor, decoded for every use
+	stamps     map[string]fileStamp // Versions of the files loaded by the compilation
+	generation int                  // Last build using the entry
+}
+
+// newCompileCache creates a compile cache with the given options, applying the defaults.
+func newCompileCache(options CompileCacheOptions, logger *slog.Logger) *compileCache {
+	cache := &compileCache{
+		dir:          options.Dir,
+		compilerHash: options.CompilerHash,
+		maxSize:      options.MaxSize,
+		maxAge:       options.MaxAge,
+		logger:       logger,
+		memory:       make(map[string]*memoryCacheEntry),
+	}
+	if cache.compilerHash == "" {
+		cache.compilerHash = qjscompiler.CompilerHash()
+	}
+	if cache.maxSize <= 0 {
+		cache.maxSize = DefaultCompileCacheMaxSize
+	}
+	if cache.maxAge <= 0 {
+		cache.maxAge = DefaultCompileCacheMaxAge
+	}
+	return cache
+}
+
+// executeCached runs a request on the JS executor. With a cache, the result is served from the
+// cache when an identical request was compiled before and the files it loaded are unchanged.
+// dependencies extracts the files loaded by the compilation from its result, false if the result
+// can't be cached, e.g. it doesn't list them or it reports compile errors.
+func executeCached(cache *compileCache, jsExecutor *jsexecutor.JsExecutor, request *jsexecutor.JsRequest, dependencies func(result interface{}) ([]string, bool)) (interface{}, error) {
+	execute := func() (interface{}, error) {
+		jsResponse, err := jsExecutor.Execute(request)
+		if err != nil {
+			return nil, err
+		}
+		return jsResponse.Result, nil
+	}
+	if cache == nil {
+		return execute()
+	}
+
+	key, err := cache.key(request)
+	if err != nil {
+		cache.logger.Warn("Failed to compute compile cache key", "error", err, "service", request.Service)
+		return execute()
+	}
+
+	// Step 1: Look up the results of the recent builds, then the persistent cache
+	if result, ok := cache.getMemory(key); ok {
+		return result, nil
+	}
+	if cache.dir != "" {
+		if data, files, ok := cache.get(key); ok {
+			var result interface{}
+			if err := json.Unmarshal(data, &result); err == nil {
+				cache.putMemory(key, data, files)
+				return result, nil
+			}
+		}
+	}
+
+	// Step 2: Compile and store the result
+	// Without the loaded files, a cached result can't be invalidated when one of them changes
+	result, err := execute()
+	if err != nil {
+		return nil, err
+	}
+	files, ok := dependencies(result)
+	if !ok {
+		cache.logger.Debug("Compile result can't be cached", "service", request.Service)
+		return result, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		cache.logger.Warn("Failed to encode compile cache entry", "error", err, "service", request.Service)
+		return result, nil
+	}
+	cache.putMemory(key, data, files)
+	if cache.dir != "" {
+		cache.put(key, data, files)
+	}
+	return result, nil
+}
+
+// begin starts a new build. Entries of the memory cache unused by the previous build are dropped,
+// they belong to sources that have been edited or removed since.
+func (c *compileCache) begin() {
+	c.memoryMu.Lock()
+	defer c.memoryMu.Unlock()
+	for key, entry := range c.memory {
+		if entry.generation < c.generation {
+			delete(c.memory, key)
+		}
+	}
+	c.generation++
+}
+
+// getMemory returns the result of a key from the memory cache, if the files loaded by the
+// compilation have not been modified since.
+func (c *compileCache) getMemory(key string) (interface{}, bool) {
+	c.memoryMu.Lock()
+	entry, ok := c.memory[key]
+	c.memoryMu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	for file, stamp := range entry.stamps {
+		if current, err := statFile(file); err != nil || current.size != stamp.size || !current.modTime.Equal(stamp.modTime) {
+			c.memoryMu.Lock()
+			delete(c.memory, key)
+			c.memoryMu.Unlock()
+			return nil, false
+		}
+	}
+
+	// Every use gets its own copy of the result
+	var result interface{}
+	if err := json.Unmarshal(entry.result, &result); err != nil {
+		return nil, false
+	}
+	c.memoryMu.Lock()
+	entry.generation = c.generation
+	c.memoryMu.Unlock()
+	return result, true
+}
+
+// putMemory stores the result of a key in the memory cache.
+// Results whose dependencies can't be read are not cached.
+func (c *compileCache) putMemory(key string, data json.RawMessage, dependencies []string) {
+	stamps := make(map[string]fileStamp, len(dependencies))
+	for _, file := range dependencies {
+		stamp, err := statFile(file)
+		if err != nil {
+			return
+		}
+		stamps[file] = stamp
+	}
+
+	c.memoryMu.Lock()
+	defer c.memoryMu.Unlock()
+	c.memory[key] = &memoryCacheEntry{result: data, stamps: stamps, generation: c.generation}
+}
+
+// key derives the cache key of a request from the compiler bundle, the service and its arguments.
+func (c *compileCache) key(request *jsexecutor.JsRequest) (string, error) {
+	data, err := json.Marshal([]interface{}{compileCacheFormat, c.compilerHash, request.Service, request.Args})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// entryPath returns the path of the cache file of a key, entries are spread over subdirectories.
+func (c *compileCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// get returns the encoded result of a key from the disk and the files loaded by its compilation.
+// Entries whose dependencies changed and unreadable entries are removed. Hits refresh the
+// modification time of the entry, which orders eviction.
+func (c *compileCache) get(key string) (json.RawMessage, []string, bool) {
+	c.scan.Do(c.evict)
+
+	path := c.entryPath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	var entry compileCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || len(entry.Result) == 0 {
+		c.remove(path)
+		return nil, nil, false
+	}
+	files := make([]string, 0, len(entry.Dependencies))
+	for file, hash := range entry.Dependencies {
+		if current, err := hashFile(file); err != nil || current != hash {
+			c.remove(path)
+			return nil, nil, false
+		}
+		files = append(files, file)
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return entry.Result, files, true
+}
+
+// put stores the encoded result of a key on disk together with the hashes of its dependencies.
+// Results whose dependencies can't be read are not cached.
+func (c *compileCache) put(key string, result json.RawMessage, dependencies []string) {
+	c.scan.Do(c.evict)
+
+	entry := compileCacheEntry{Result: result, Dependencies: make(map[string]string, len(dependencies))}
+	for _, file := range dependencies {
+		hash, err := hashFile(file)
+		if err != nil {
+			return
+		}
+		entry.Dependencies[file] = hash
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		c.logger.Warn("Failed to encode compile cache entry", "error", err)
+		return
+	}
+
+	path := c.entryPath(key)
+	previousSize := int64(0)
+	if info, err := os.Stat(path); err == nil {
+		previousSize = info.Size()
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		c.logger.Warn("Failed to write compile cache entry", "error", err, "file", path)
+		return
+	}
+
+	c.mu.Lock()
+	c.size += int64(len(data)) - previousSize
+	exceeded := c.size > c.maxSize
+	c.mu.Unlock()
+	if exceeded {
+		c.evict()
+	}
+}
+
+// remove deletes an entry and accounts for its size.
+func (c *compileCache) remove(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if err := os.Remove(path); err == nil {
+		c.mu.Lock()
+		c.size -= info.Size()
+		c.mu.Unlock()
+	}
+}
+
+// evict removes the entries unused for longer than maxAge and, if the cache is still larger
+// than maxSize, the least recently used entries until it is below 80% of maxSize.
+// Leftover temporary files of interrupted writes are removed as well. Other processes may
+// share the directory, files vanishing during the scan are ignored.
+func (c *compileCache) evict() {
+	type cacheFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var files []cacheFile
+	var size int64
+	now := time.Now()
+	_ = filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			if now.Sub(info.ModTime()) > time.Hour {
+				_ = os.Remove(path)
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		if now.Sub(info.ModTime()) > c.maxAge {
+			_ = os.Remove(path)
+			return nil
+		}
+		files = append(files, cacheFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		size += info.Size()
+		return nil
+	})
+
+	if size > c.maxSize {
+		sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+		for _, file := range files {
+			if size <= c.maxSize*8/10 {
+				break
+			}
+			if err := os.Remove(file.path); err == nil || os.IsNotExist(err) {
+				size -= file.size
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.size = size
+	c.mu.Unlock()
+}
+
+// writeFileAtomic writes a file through a temporary file in the same directory, so readers
+// never see a partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// hashFile hashes the content of a file.
+func hashFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(xxhash.Sum64(data), 16), nil
+}
+
+// statFile returns the version of a file, without reading it.
+func statFile(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}, nil
+}
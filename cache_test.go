@@ -0,0 +1,571 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qjscompiler "github.com/buke/esbuild-plugin-vue-go/engines/quickjs-go"
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestNewCompileCacheDefaults(t *testing.T) {
+	cache := newCompileCache(CompileCacheOptions{Dir: t.TempDir()}, slog.Default())
+	if cache.maxSize != DefaultCompileCacheMaxSize || cache.maxAge != DefaultCompileCacheMaxAge {
+		t.Errorf("Expected default limits, got %d, %v", cache.maxSize, cache.maxAge)
+	}
+	if cache.compilerHash != qjscompiler.CompilerHash() {
+		t.Errorf("Expected the hash of the embedded compiler, got %q", cache.compilerHash)
+	}
+
+	cache = newCompileCache(CompileCacheOptions{Dir: t.TempDir(), CompilerHash: "abc", MaxSize: 10, MaxAge: time.Hour}, slog.Default())
+	if cache.compilerHash != "abc" || cache.maxSize != 10 || cache.maxAge != time.Hour {
+		t.Errorf("Expected configured options, got %+v", cache)
+	}
+}
+
+func TestCompileCacheKey(t *testing.T) {
+	cache := newCompileCache(CompileCacheOptions{Dir: t.TempDir(), CompilerHash: "v1"}, slog.Default())
+	request := func(source string) *jsexecutor.JsRequest {
+		return &jsexecutor.JsRequest{Id: source, Service: "sfc.vue.compileSFC", Args: []interface{}{"id", "/src/App.vue", source, map[string]interface{}{"isProd": true}}}
+	}
+
+	key1, err := cache.key(request("a"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	key2, _ := cache.key(&jsexecutor.JsRequest{Id: "other", Service: "sfc.vue.compileSFC", Args: request("a").Args})
+	if key1 != key2 {
+		t.Error("Expected the request ID not to be part of the key")
+	}
+	if key3, _ := cache.key(request("b")); key3 == key1 {
+		t.Error("Expected the source to be part of the key")
+	}
+	other := newCompileCache(CompileCacheOptions{Dir: t.TempDir(), CompilerHash: "v2"}, slog.Default())
+	if key4, _ := other.key(request("a")); key4 == key1 {
+		t.Error("Expected the compiler hash to be part of the key")
+	}
+	if _, err := cache.key(&jsexecutor.JsRequest{Args: []interface{}{func() {}}}); err == nil {
+		t.Error("Expected error for arguments that can't be encoded")
+	}
+}
+
+func TestCompileCacheGetPut(t *testing.T) {
+	tmpDir := t.TempDir()
+	partial := filepath.Join(tmpDir, "_variables.scss")
+	if err := os.WriteFile(partial, []byte("$a: red;"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	cache := newCompileCache(CompileCacheOptions{Dir: filepath.Join(tmpDir, "cache")}, slog.Default())
+	key := "0123456789abcdef"
+
+	if _, _, ok := cache.get(key); ok {
+		t.Fatal("Expected miss for an empty cache")
+	}
+
+	cache.put(key, json.RawMessage(`{"css":".a {}","line":3}`), []string{partial})
+	result, files, ok := cache.get(key)
+	if !ok {
+		t.Fatal("Expected hit after put")
+	}
+	if string(result) != `{"css":".a {}","line":3}` {
+		t.Errorf("Expected cached result, got %s", result)
+	}
+	if !reflect.DeepEqual(files, []string{partial}) {
+		t.Errorf("Expected dependencies %v, got %v", []string{partial}, files)
+	}
+
+	// Changing a dependency invalidates the entry
+	if err := os.WriteFile(partial, []byte("$a: blue;"), 0644); err != nil {
+		t.Fatalf("Failed to update file: %v", err)
+	}
+	if _, _, ok := cache.get(key); ok {
+		t.Error("Expected miss after a dependency changed")
+	}
+	if _, err := os.Stat(cache.entryPath(key)); !os.IsNotExist(err) {
+		t.Error("Expected stale entry to be removed")
+	}
+
+	// Results with unreadable dependencies are not cached
+	cache.put(key, json.RawMessage(`"result"`), []string{filepath.Join(tmpDir, "missing.scss")})
+	if _, _, ok := cache.get(key); ok {
+		t.Error("Expected result with missing dependency not to be cached")
+	}
+
+	// Corrupted entries are treated as misses
+	if err := os.WriteFile(cache.entryPath(key), []byte("{invalid"), 0644); err != nil {
+		t.Fatalf("Failed to corrupt entry: %v", err)
+	}
+	if _, _, ok := cache.get(key); ok {
+		t.Error("Expected miss for a corrupted entry")
+	}
+}
+
+func TestCompileCacheMemory(t *testing.T) {
+	tmpDir := t.TempDir()
+	partial := filepath.Join(tmpDir, "_variables.scss")
+	if err := os.WriteFile(partial, []byte("$a: red;"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	cache := newCompileCache(CompileCacheOptions{}, slog.Default())
+	cache.begin()
+
+	cache.putMemory("a", json.RawMessage(`{"css":".a {}","line":3}`), []string{partial})
+	result, ok := cache.getMemory("a")
+	if !ok {
+		t.Fatal("Expected hit after put")
+	}
+	expected := map[string]interface{}{"css": ".a {}", "line": float64(3)}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Every use gets its own copy
+	result.(map[string]interface{})["css"] = "changed"
+	if result, _ := cache.getMemory("a"); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected cached result to be unaffected by changes of a copy, got %v", result)
+	}
+
+	// Entries used by the previous build are kept, others are dropped
+	cache.putMemory("b", json.RawMessage(`"b"`), nil)
+	cache.begin()
+	if _, ok := cache.getMemory("b"); !ok {
+		t.Error("Expected entry of the previous build to be kept")
+	}
+	cache.begin()
+	cache.begin()
+	if _, ok := cache.getMemory("b"); ok {
+		t.Error("Expected entry unused by the previous build to be dropped")
+	}
+
+	// Modifying a dependency invalidates the entry
+	cache.putMemory("a", json.RawMessage(`"a"`), []string{partial})
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(partial, modTime, modTime); err != nil {
+		t.Fatalf("Failed to update file: %v", err)
+	}
+	if _, ok := cache.getMemory("a"); ok {
+		t.Error("Expected miss after a dependency changed")
+	}
+
+	// Results with unreadable dependencies are not cached
+	cache.putMemory("c", json.RawMessage(`"c"`), []string{filepath.Join(tmpDir, "missing.scss")})
+	if _, ok := cache.getMemory("c"); ok {
+		t.Error("Expected result with missing dependency not to be cached")
+	}
+}
+
+func TestCompileCacheEvict(t *testing.T) {
+	dir := t.TempDir()
+	cache := newCompileCache(CompileCacheOptions{Dir: dir, MaxSize: 400, MaxAge: time.Hour}, slog.Default())
+
+	keys := []string{"aa00", "bb00", "cc00", "dd00"}
+	for i, key := range keys {
+		cache.put(key, json.RawMessage(`{"css":"`+strings.Repeat("x", 80)+`"}`), nil)
+		// Older entries are evicted first
+		modTime := time.Now().Add(time.Duration(i-len(keys)) * time.Minute)
+		if err := os.Chtimes(cache.entryPath(key), modTime, modTime); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+	}
+
+	if cache.size > 400 {
+		t.Errorf("Expected cache size below the limit, got %d", cache.size)
+	}
+	if _, err := os.Stat(cache.entryPath("aa00")); !os.IsNotExist(err) {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if _, _, ok := cache.get("dd00"); !ok {
+		t.Error("Expected most recent entry to be kept")
+	}
+
+	// Entries unused for longer than the maximum age are evicted
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(cache.entryPath("dd00"), old, old); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+	cache.evict()
+	if _, err := os.Stat(cache.entryPath("dd00")); !os.IsNotExist(err) {
+		t.Error("Expected expired entry to be evicted")
+	}
+}
+
+func TestExecuteCached(t *testing.T) {
+	var requests int32
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Sass: &MockSassConfig{CSS: ".a { color: red; }"},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			atomic.AddInt32(&requests, 1)
+		},
+	})
+	request := func() *jsexecutor.JsRequest {
+		return &jsexecutor.JsRequest{Id: "1", Service: "sfc.sass.renderSync", Args: []interface{}{map[string]interface{}{"data": ".a {}"}}}
+	}
+	noDependencies := func(interface{}) ([]string, bool) { return nil, true }
+
+	// Without a cache every request runs on the executor
+	for i := 0; i < 2; i++ {
+		if _, err := executeCached(nil, jsExec, request(), noDependencies); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests without cache, got %d", requests)
+	}
+
+	cache := newCompileCache(CompileCacheOptions{Dir: t.TempDir()}, slog.Default())
+	for i := 0; i < 2; i++ {
+		result, err := executeCached(cache, jsExec, request(), noDependencies)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if css, _ := result.(map[string]interface{})["css"].(string); css != ".a { color: red; }" {
+			t.Errorf("Expected compiled CSS, got %v", result)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("Expected the second request to be served from the cache, got %d requests", requests)
+	}
+
+	// Without a directory results are only kept in memory
+	memoryOnly := newCompileCache(CompileCacheOptions{}, slog.Default())
+	for i := 0; i < 2; i++ {
+		if _, err := executeCached(memoryOnly, jsExec, request(), noDependencies); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if requests != 4 {
+		t.Errorf("Expected the second request to be served from memory, got %d requests", requests)
+	}
+
+	// Results without the list of loaded files are never cached
+	unknownDependencies := func(interface{}) ([]string, bool) { return nil, false }
+	uncached := newCompileCache(CompileCacheOptions{Dir: t.TempDir()}, slog.Default())
+	for i := 0; i < 2; i++ {
+		if _, err := executeCached(uncached, jsExec, request(), unknownDependencies); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if requests != 6 {
+		t.Errorf("Expected results without loaded files not to be cached, got %d requests", requests)
+	}
+}
+
+// Integration tests
+
+func TestVueCompileCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	vueFile := filepath.Join(tmpDir, "App.vue")
+	if err := os.WriteFile(vueFile, []byte(`<template><div>Test</div></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	cacheDir := filepath.Join(tmpDir, ".cache")
+
+	var compiles int32
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template:     &MockTemplateConfig{Code: "export function render() { return 'cached'; }"},
+		Dependencies: []interface{}{},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				atomic.AddInt32(&compiles, 1)
+			}
+		},
+	})
+
+	// Every build uses a new plugin instance, as a new process would
+	var outputs []string
+	for i := 0; i < 2; i++ {
+		result := api.Build(api.BuildOptions{
+			EntryPoints:   []string{entryFile},
+			Bundle:        true,
+			Write:         false,
+			LogLevel:      api.LogLevelError,
+			AbsWorkingDir: tmpDir,
+			Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithCompileCache(cacheDir))},
+		})
+		if len(result.Errors) > 0 {
+			t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+		}
+		outputs = append(outputs, string(result.OutputFiles[0].Contents))
+	}
+
+	if compiles != 1 {
+		t.Errorf("Expected the second build to use the compile cache, got %d compilations", compiles)
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("Expected identical output from the cache, got:\n%s\n---\n%s", outputs[0], outputs[1])
+	}
+}
+
+// TestVueCompileCacheErrors tests that failed compilations are compiled again once the missing file exists
+func TestVueCompileCacheErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue":  `<template><div>Test</div></template><style src="./app.css"></style>`,
+		"entry.js": `import App from './App.vue'; console.log(App);`,
+	})
+	cacheDir := filepath.Join(tmpDir, ".cache")
+
+	// The compiler reports the missing style source, which isn't among the loaded files
+	var compiles int32
+	mockConfig := &MockEngineConfig{
+		Template:      &MockTemplateConfig{Code: "export function render() { return null; }"},
+		CompileErrors: []interface{}{map[string]interface{}{"text": "Failed to load ./app.css", "line": 1}},
+		Dependencies:  []interface{}{},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				atomic.AddInt32(&compiles, 1)
+			}
+		},
+	}
+	jsExec := newMockExecutor(t, mockConfig)
+	build := func() api.BuildResult {
+		return api.Build(api.BuildOptions{
+			EntryPoints:   []string{filepath.Join(tmpDir, "entry.js")},
+			Bundle:        true,
+			Write:         false,
+			LogLevel:      api.LogLevelSilent,
+			AbsWorkingDir: tmpDir,
+			Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithCompileCache(cacheDir))},
+		})
+	}
+
+	if result := build(); len(result.Errors) == 0 {
+		t.Fatal("Expected the missing style source to fail the build")
+	}
+
+	writeProjectFiles(t, tmpDir, map[string]string{"app.css": ".app { color: red; }"})
+	mockConfig.CompileErrors = nil
+	mockConfig.Dependencies = []interface{}{filepath.Join(tmpDir, "app.css")}
+	if result := build(); len(result.Errors) > 0 {
+		t.Fatalf("Expected the failed compilation not to be cached, got errors: %v", result.Errors)
+	}
+	if compiles != 2 {
+		t.Errorf("Expected the component to be compiled again, got %d compilations", compiles)
+	}
+}
+
+func TestVueRebuildCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	vueFile := filepath.Join(tmpDir, "App.vue")
+	if err := os.WriteFile(vueFile, []byte(`<template><div>Test</div></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	var compiles int32
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template:     &MockTemplateConfig{Code: "export function render() { return null; }"},
+		Dependencies: []interface{}{},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				atomic.AddInt32(&compiles, 1)
+			}
+		},
+	})
+
+	newContext := func(options ...OptionFunc) api.BuildContext {
+		ctx, ctxErr := api.Context(api.BuildOptions{
+			EntryPoints:   []string{entryFile},
+			Bundle:        true,
+			Write:         false,
+			LogLevel:      api.LogLevelError,
+			AbsWorkingDir: tmpDir,
+			Plugins:       []api.Plugin{NewPlugin(append([]OptionFunc{WithJsExecutor(jsExec)}, options...)...)},
+		})
+		if ctxErr != nil {
+			t.Fatalf("Failed to create build context: %v", ctxErr)
+		}
+		return ctx
+	}
+
+	// Without the memory cache every rebuild compiles the components
+	uncached := newContext()
+	for i := 0; i < 2; i++ {
+		if result := uncached.Rebuild(); len(result.Errors) > 0 {
+			t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+		}
+	}
+	uncached.Dispose()
+	if compiles != 2 {
+		t.Errorf("Expected every rebuild to compile without the memory cache, got %d compilations", compiles)
+	}
+
+	compiles = 0
+	ctx := newContext(WithMemoryCache())
+	defer ctx.Dispose()
+	for i := 0; i < 2; i++ {
+		if result := ctx.Rebuild(); len(result.Errors) > 0 {
+			t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+		}
+	}
+	if compiles != 1 {
+		t.Errorf("Expected unchanged component not to be recompiled, got %d compilations", compiles)
+	}
+
+	// Edited components are compiled again
+	if err := os.WriteFile(vueFile, []byte(`<template><div>Edited</div></template>`), 0644); err != nil {
+		t.Fatalf("Failed to update Vue file: %v", err)
+	}
+	if result := ctx.Rebuild(); len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if compiles != 2 {
+		t.Errorf("Expected edited component to be recompiled, got %d compilations", compiles)
+	}
+}
+
+// TestVueRebuildCacheErrors tests that failed compilations kept in memory don't outlive the missing file
+func TestVueRebuildCacheErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue":  `<template><div>Test</div></template><style src="./app.css"></style>`,
+		"entry.js": `import App from './App.vue'; console.log(App);`,
+	})
+
+	var compiles int32
+	mockConfig := &MockEngineConfig{
+		Template:      &MockTemplateConfig{Code: "export function render() { return null; }"},
+		CompileErrors: []interface{}{map[string]interface{}{"text": "Failed to load ./app.css", "line": 1}},
+		Dependencies:  []interface{}{},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				atomic.AddInt32(&compiles, 1)
+			}
+		},
+	}
+	ctx, ctxErr := api.Context(api.BuildOptions{
+		EntryPoints:   []string{filepath.Join(tmpDir, "entry.js")},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(newMockExecutor(t, mockConfig)), WithMemoryCache())},
+	})
+	if ctxErr != nil {
+		t.Fatalf("Failed to create build context: %v", ctxErr)
+	}
+	defer ctx.Dispose()
+
+	if result := ctx.Rebuild(); len(result.Errors) == 0 {
+		t.Fatal("Expected the missing style source to fail the build")
+	}
+
+	writeProjectFiles(t, tmpDir, map[string]string{"app.css": ".app { color: red; }"})
+	mockConfig.CompileErrors = nil
+	mockConfig.Dependencies = []interface{}{filepath.Join(tmpDir, "app.css")}
+	if result := ctx.Rebuild(); len(result.Errors) > 0 {
+		t.Fatalf("Expected the failed compilation not to be kept in memory, got errors: %v", result.Errors)
+	}
+	if compiles != 2 {
+		t.Errorf("Expected the component to be compiled again, got %d compilations", compiles)
+	}
+}
+
+func TestSassRebuildCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"app.scss":        "@use 'variables';\n.app { color: variables.$primary; }",
+		"_variables.scss": "$primary: red;",
+		"entry.js":        `import './app.scss';`,
+	})
+	partial := filepath.Join(tmpDir, "_variables.scss")
+
+	mockConfig := &MockEngineConfig{
+		Sass: &MockSassConfig{CSS: ".app { color: red; }", Stats: &MockSassStatsConfig{IncludedFiles: []string{partial}}},
+	}
+	ctx, ctxErr := api.Context(api.BuildOptions{
+		EntryPoints:   []string{filepath.Join(tmpDir, "entry.js")},
+		Bundle:        true,
+		Write:         false,
+		Outdir:        filepath.Join(tmpDir, "dist"),
+		LogLevel:      api.LogLevelError,
+		AbsWorkingDir: tmpDir,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(newMockExecutor(t, mockConfig)), WithMemoryCache())},
+	})
+	if ctxErr != nil {
+		t.Fatalf("Failed to create build context: %v", ctxErr)
+	}
+	defer ctx.Dispose()
+
+	rebuild := func() string {
+		result := ctx.Rebuild()
+		if len(result.Errors) > 0 {
+			t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+		}
+		return outputFile(result, ".css")
+	}
+	if css := rebuild(); !strings.Contains(css, "red") {
+		t.Fatalf("Expected the compiled stylesheet, got:\n%s", css)
+	}
+
+	// Editing the partial compiles the stylesheet again
+	if err := os.WriteFile(partial, []byte("$primary: #42b883;"), 0644); err != nil {
+		t.Fatalf("Failed to update the partial: %v", err)
+	}
+	mockConfig.Sass.CSS = ".app { color: #42b883; }"
+	if css := rebuild(); !strings.Contains(css, "#42b883") {
+		t.Errorf("Expected the edited partial to change the output, got:\n%s", css)
+	}
+}
+
+// TestSassRebuildCacheWithCompiler tests that editing a partial changes the output of a cached rebuild
+func TestSassRebuildCacheWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue":         "<template><div class=\"app\"></div></template>\n<style lang=\"scss\">\n@use 'variables';\n.app { color: variables.$primary; }\n</style>",
+		"_variables.scss": "$primary: red;",
+		"main.js":         `import App from './App.vue'; console.log(App);`,
+	})
+
+	ctx, ctxErr := api.Context(api.BuildOptions{
+		EntryPoints:   []string{filepath.Join(tmpDir, "main.js")},
+		Bundle:        true,
+		Write:         false,
+		Outdir:        filepath.Join(tmpDir, "dist"),
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		External:      []string{"vue"},
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(newCompilerExecutor(t)), WithMemoryCache())},
+	})
+	if ctxErr != nil {
+		t.Fatalf("Failed to create build context: %v", ctxErr)
+	}
+	defer ctx.Dispose()
+
+	rebuild := func() string {
+		result := ctx.Rebuild()
+		if len(result.Errors) > 0 {
+			t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+		}
+		return outputFile(result, ".css")
+	}
+	if css := rebuild(); !strings.Contains(css, "red") {
+		t.Fatalf("Expected the compiled style, got:\n%s", css)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "_variables.scss"), []byte("$primary: #42b883;"), 0644); err != nil {
+		t.Fatalf("Failed to update the partial: %v", err)
+	}
+	if css := rebuild(); !strings.Contains(css, "#42b883") {
+		t.Errorf("Expected the edited partial to change the output, got:\n%s", css)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// CustomBlock describes a custom block of a Vue Single File Component, such as <i18n> or <docs>.
+// It is passed to the CustomBlockProcessor registered for the block's tag name.
+type CustomBlock struct {
+	Type     string         // Tag name of the block, e.g. "i18n"
+	Content  string         // Raw content of the block
+	Attrs    map[string]any // Block attributes, attributes without a value are set to true
+	Lang     string         // Value of the lang attribute, empty if not set
+	Index    int            // Index of the block among all custom blocks of the component
+	Line     int            // 1-based line of the block in the .vue file
+	FilePath string         // Path of the .vue file containing the block
+}
+
+// newCustomBlock converts a custom block from the compilation result into a CustomBlock.
+// Missing or invalid fields are left at their zero values.
+func newCustomBlock(filePath string, index int, raw map[string]interface{}) CustomBlock {
+	block := CustomBlock{
+		Index:    index,
+		FilePath: filePath,
+		Attrs:    map[string]any{},
+	}
+	block.Type, _ = raw["type"].(string)
+	block.Content, _ = raw["content"].(string)
+	block.Lang, _ = raw["lang"].(string)
+	block.Line = blockLine(raw)
+	if attrs, ok := raw["attrs"].(map[string]interface{}); ok {
+		block.Attrs = attrs
+	}
+	return block
+}
+
+// collectCustomBlocks splits the custom blocks from the compilation result into blocks
+// handled by a registered processor and warnings for blocks that are dropped.
+// The returned blocks keep their original index, which is used to import them from the entry module.
+func collectCustomBlocks(opts *Options, filePath string, rawBlocks interface{}) ([]map[string]interface{}, []map[string]interface{}, []api.Message) {
+	items, ok := rawBlocks.([]interface{})
+	if !ok {
+		return nil, nil, nil
+	}
+
+	all := make([]map[string]interface{}, len(items))
+	var processed []map[string]interface{}
+	var warnings []api.Message
+	for i, item := range items {
+		raw, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		all[i] = raw
+
+		block := newCustomBlock(filePath, i, raw)
+		if _, exists := opts.customBlockProcessors[block.Type]; exists {
+			processed = append(processed, map[string]interface{}{"index": i, "type": block.Type})
+			continue
+		}
+
+		// Blocks without a processor would otherwise vanish silently
+		warnings = append(warnings, api.Message{
+			Text: fmt.Sprintf("Custom block <%s> is ignored, no processor is registered for it (see WithCustomBlockProcessor)", block.Type),
+			Location: &api.Location{
+				File: filePath,
+				Line: block.Line,
+			},
+		})
+	}
+	return all, processed, warnings
+}
+
+// registerCustomBlockHandler registers the custom block handler for Vue Single File Components.
+// Loads the custom block specified by the index in URL parameters and runs the processor
+// registered for its tag name. The returned JavaScript is resolved relative to the .vue file.
+func registerCustomBlockHandler(opts *Options, build *api.PluginBuild) {
+	build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: "sfc-custom"}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+		pluginData := args.PluginData.(map[string]interface{})
+
+		// Extract custom block index from URL query parameters
+		parsedURL, _ := url.Parse(args.Path)
+		index, _ := strconv.Atoi(parsedURL.Query().Get("index"))
+		filePath := strings.SplitN(args.Path, "?", 2)[0]
+
+		// Load the specific custom block by index
+		customBlocks, _ := pluginData["customBlocks"].([]map[string]interface{})
+		if index < 0 || index >= len(customBlocks) || customBlocks[index] == nil {
+			return api.OnLoadResult{}, fmt.Errorf("custom block %d not found in %s", index, filePath)
+		}
+		block := newCustomBlock(filePath, index, customBlocks[index])
+
+		processor, exists := opts.customBlockProcessors[block.Type]
+		if !exists {
+			return api.OnLoadResult{}, fmt.Errorf("no processor registered for custom block <%s>", block.Type)
+		}
+
+		// Run the processor, errors are reported at the block position in the .vue file
+		code, err := processor(block, build.InitialOptions)
+		if err != nil {
+			opts.logger.Error("Failed to process custom block", "error", err, "block", block.Type, "file", filePath)
+			return api.OnLoadResult{
+				Errors: []api.Message{{
+					Text: fmt.Sprintf("Custom block <%s> processor failed: %v", block.Type, err),
+					Location: &api.Location{
+						File: filePath,
+						Line: block.Line,
+					},
+				}},
+			}, nil
+		}
+
+		return api.OnLoadResult{
+			Contents:   &code,
+			Loader:     api.LoaderJS,
+			ResolveDir: filepath.Dir(filePath),
+		}, nil
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// buildCustomBlockTest builds a .vue file with the given mock config and plugin options
+func buildCustomBlockTest(t *testing.T, mockConfig *MockEngineConfig, optsFunc ...OptionFunc) api.BuildResult {
+	t.Helper()
+
+	tmpFile := createTempVueFile(t, "<template><div>Test</div></template>\n<i18n lang=\"json\">{}</i18n>")
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	entryContent := fmt.Sprintf(`import App from '%s'; console.log(App);`, filepath.Base(tmpFile))
+	if err := os.WriteFile(entryFile, []byte(entryContent), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(entryFile) })
+
+	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(mockConfig)))
+	if err != nil {
+		t.Fatalf("Failed to create JS executor: %v", err)
+	}
+	if err := jsExec.Start(); err != nil {
+		t.Fatalf("Failed to start JS executor: %v", err)
+	}
+	t.Cleanup(func() { jsExec.Stop() })
+
+	return api.Build(api.BuildOptions{
+		EntryPoints: []string{entryFile},
+		Bundle:      true,
+		Write:       false,
+		LogLevel:    api.LogLevelSilent,
+		Plugins:     []api.Plugin{NewPlugin(append([]OptionFunc{WithJsExecutor(jsExec)}, optsFunc...)...)},
+	})
+}
+
+// TestNewCustomBlock tests conversion of compiled custom blocks
+func TestNewCustomBlock(t *testing.T) {
+	block := newCustomBlock("/src/App.vue", 2, map[string]interface{}{
+		"type":    "i18n",
+		"content": `{"en": {"hello": "Hello"}}`,
+		"lang":    "json",
+		"line":    float64(12),
+		"attrs":   map[string]interface{}{"lang": "json", "global": true},
+	})
+
+	if block.Type != "i18n" || block.Lang != "json" || block.Index != 2 || block.Line != 12 {
+		t.Errorf("Unexpected custom block: %+v", block)
+	}
+	if block.FilePath != "/src/App.vue" || block.Attrs["global"] != true {
+		t.Errorf("Unexpected custom block file or attributes: %+v", block)
+	}
+
+	// Invalid fields are left empty
+	empty := newCustomBlock("/src/App.vue", 0, map[string]interface{}{"type": 42, "attrs": "invalid"})
+	if empty.Type != "" || empty.Attrs == nil || empty.Line != 1 {
+		t.Errorf("Expected zero values for invalid fields, got %+v", empty)
+	}
+}
+
+// TestCollectCustomBlocks tests splitting custom blocks into processed blocks and warnings
+func TestCollectCustomBlocks(t *testing.T) {
+	opts := newOptions()
+	WithCustomBlockProcessor("i18n", func(block CustomBlock, buildOptions *api.BuildOptions) (string, error) {
+		return "", nil
+	})(opts)
+
+	all, processed, warnings := collectCustomBlocks(opts, "/src/App.vue", []interface{}{
+		map[string]interface{}{"type": "docs", "line": float64(3)},
+		"invalid",
+		map[string]interface{}{"type": "i18n", "line": float64(7)},
+	})
+
+	if len(all) != 3 || all[1] != nil {
+		t.Errorf("Expected all blocks to keep their index, got %v", all)
+	}
+	if len(processed) != 1 || processed[0]["index"] != 2 {
+		t.Errorf("Expected only the i18n block to be processed, got %v", processed)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Text, "<docs>") || warnings[0].Location.Line != 3 {
+		t.Errorf("Expected a located warning for the docs block, got %v", warnings)
+	}
+
+	if all, processed, warnings := collectCustomBlocks(opts, "/src/App.vue", nil); all != nil || processed != nil || warnings != nil {
+		t.Error("Expected nothing for missing custom blocks")
+	}
+}
+
+// TestCustomBlockProcessorBuild tests that processed custom blocks are applied to the component
+func TestCustomBlockProcessorBuild(t *testing.T) {
+	var received CustomBlock
+	result := buildCustomBlockTest(t,
+		&MockEngineConfig{
+			CustomBlocks: []interface{}{
+				map[string]interface{}{"type": "i18n", "content": `{"en":{"hello":"Hello"}}`, "lang": "json", "line": float64(2)},
+				map[string]interface{}{"type": "docs", "content": "# Docs", "line": float64(3)},
+			},
+		},
+		WithCustomBlockProcessor("i18n", func(block CustomBlock, buildOptions *api.BuildOptions) (string, error) {
+			received = block
+			return fmt.Sprintf("export default function (Comp) { Comp.__i18n = [%s]; }", block.Content), nil
+		}),
+	)
+
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if received.Type != "i18n" || received.Lang != "json" {
+		t.Errorf("Expected processor to receive the i18n block, got %+v", received)
+	}
+
+	output := string(result.OutputFiles[0].Contents)
+	if !strings.Contains(output, "__i18n") || !strings.Contains(output, "Hello") {
+		t.Errorf("Expected output to contain the processed i18n block, got:\n%s", output)
+	}
+
+	warnings := filterVuePluginWarnings(result.Warnings)
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Text, "<docs>") {
+		t.Errorf("Expected a warning for the unprocessed docs block, got %v", warnings)
+	}
+}
+
+// TestCustomBlockProcessorWithCompiler tests that the compiler bundle reports custom blocks to their processor
+func TestCustomBlockProcessorWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue": "<template><div></div></template>\n\n<i18n lang=\"json\">\n{\"en\":{\"hello\":\"Hello\"}}\n</i18n>",
+		"main.js": `import App from './App.vue'; console.log(App);`,
+	})
+
+	var received CustomBlock
+	result := buildWithCompiler(t, tmpDir, "main.js", nil,
+		WithCustomBlockProcessor("i18n", func(block CustomBlock, buildOptions *api.BuildOptions) (string, error) {
+			received = block
+			return fmt.Sprintf("export default function (Comp) { Comp.__i18n = [%s]; }", block.Content), nil
+		}),
+	)
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if received.Type != "i18n" || received.Lang != "json" || received.Line != 3 {
+		t.Errorf("Expected processor to receive the i18n block on line 3, got %+v", received)
+	}
+	if output := outputFile(result, ".js"); !strings.Contains(output, "__i18n") {
+		t.Errorf("Expected output to contain the processed i18n block, got:\n%s", output)
+	}
+}
+
+// TestCustomBlockProcessorError tests that processor errors are reported at the block
+func TestCustomBlockProcessorError(t *testing.T) {
+	result := buildCustomBlockTest(t,
+		&MockEngineConfig{
+			CustomBlocks: []interface{}{
+				map[string]interface{}{"type": "i18n", "content": "invalid", "line": float64(2)},
+			},
+		},
+		WithCustomBlockProcessor("i18n", func(block CustomBlock, buildOptions *api.BuildOptions) (string, error) {
+			return "", fmt.Errorf("invalid JSON")
+		}),
+	)
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected 1 build error, got %v", result.Errors)
+	}
+	if !strings.Contains(result.Errors[0].Text, "invalid JSON") || result.Errors[0].Location.Line != 2 {
+		t.Errorf("Expected located processor error, got %+v", result.Errors[0])
+	}
+}
+
+// TestGenerateEntryContentsWithCustomBlocks tests that processed custom blocks are imported
+func TestGenerateEntryContentsWithCustomBlocks(t *testing.T) {
+	contents, _, err := generateEntryContents(&entryOptions{
+		filePath:     "test.vue",
+		dataId:       "data-v-test",
+		script:       map[string]interface{}{"content": "export default {}"},
+		customBlocks: []map[string]interface{}{{"index": 1, "type": "i18n"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	for _, expected := range []string{
+		"import block1 from 'test.vue?type=custom&index=1'",
+		"if (typeof block1 === 'function') block1(script);",
+	} {
+		if !strings.Contains(contents, expected) {
+			t.Errorf("Expected output to contain '%s', got:\n%s", expected, contents)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// convertDiagnostics converts diagnostics reported by the Vue compiler into esbuild messages.
+// Each diagnostic is a map with a required "text" and optional "line" (1-based), "column" (0-based),
+// "length" and "lineText" entries, already mapped to the original .vue file by the compiler.
+// Diagnostics of external block sources (src attribute) carry a "file" entry that replaces filePath.
+// Plain strings are accepted as well and reported against the file without a position.
+// Entries of any other type are skipped to keep the build stable.
+func convertDiagnostics(raw interface{}, filePath string) []api.Message {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	messages := make([]api.Message, 0, len(items))
+	for _, item := range items {
+		switch diagnostic := item.(type) {
+		case string:
+			messages = append(messages, api.Message{
+				Text:     diagnostic,
+				Location: &api.Location{File: filePath},
+			})
+		case map[string]interface{}:
+			text, _ := diagnostic["text"].(string)
+			location := &api.Location{File: filePath}
+			if file, ok := diagnostic["file"].(string); ok && file != "" {
+				location.File = file
+			}
+
+			// Position information is optional, the compiler omits it when the error can't be located
+			if line, ok := toInt(diagnostic["line"]); ok && line > 0 {
+				location.Line = line
+				location.Column, _ = toInt(diagnostic["column"])
+				location.Length, _ = toInt(diagnostic["length"])
+				location.LineText, _ = diagnostic["lineText"].(string)
+			}
+
+			messages = append(messages, api.Message{
+				Text:     text,
+				Location: location,
+			})
+		}
+	}
+	return messages
+}
+
+// toInt converts a numeric value decoded from the JS executor into an int.
+// Numbers coming from JavaScript are usually float64, but integer types are accepted as well.
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// TestConvertDiagnostics tests conversion of compiler diagnostics to esbuild messages
+func TestConvertDiagnostics(t *testing.T) {
+	raw := []interface{}{
+		map[string]interface{}{
+			"text":     "Error parsing JavaScript expression: Unexpected token",
+			"line":     float64(3),
+			"column":   float64(10),
+			"length":   float64(4),
+			"lineText": "  <div>{{ a + }}</div>",
+		},
+		map[string]interface{}{"text": "unlocated error"},
+		"plain string warning",
+		map[string]interface{}{"text": "external error", "file": "/src/button.scss", "line": float64(2)},
+		42, // Invalid entry, should be skipped
+	}
+
+	messages := convertDiagnostics(raw, "/src/App.vue")
+	if len(messages) != 4 {
+		t.Fatalf("Expected 4 messages, got %d", len(messages))
+	}
+
+	located := messages[0]
+	if located.Location == nil || located.Location.File != "/src/App.vue" {
+		t.Fatalf("Expected location in /src/App.vue, got %+v", located.Location)
+	}
+	if located.Location.Line != 3 || located.Location.Column != 10 || located.Location.Length != 4 {
+		t.Errorf("Unexpected position: %+v", located.Location)
+	}
+	if located.Location.LineText != "  <div>{{ a + }}</div>" {
+		t.Errorf("Unexpected line text: %q", located.Location.LineText)
+	}
+
+	if messages[1].Text != "unlocated error" || messages[1].Location.Line != 0 {
+		t.Errorf("Expected unlocated message, got %+v", messages[1])
+	}
+	if messages[2].Text != "plain string warning" || messages[2].Location.File != "/src/App.vue" {
+		t.Errorf("Expected plain string message, got %+v", messages[2])
+	}
+	if messages[3].Location.File != "/src/button.scss" || messages[3].Location.Line != 2 {
+		t.Errorf("Expected message located in the external file, got %+v", messages[3].Location)
+	}
+}
+
+// TestConvertDiagnosticsInvalidInput tests conversion of missing or invalid diagnostics
+func TestConvertDiagnosticsInvalidInput(t *testing.T) {
+	for _, raw := range []interface{}{nil, "not-a-list", map[string]interface{}{}} {
+		if messages := convertDiagnostics(raw, "test.vue"); len(messages) != 0 {
+			t.Errorf("Expected no messages for %v, got %v", raw, messages)
+		}
+	}
+}
+
+// TestToInt tests numeric conversion of values decoded from the JS executor
+func TestToInt(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		expected int
+		ok       bool
+	}{
+		{float64(12), 12, true},
+		{7, 7, true},
+		{int64(5), 5, true},
+		{"12", 0, false},
+		{nil, 0, false},
+	}
+
+	for _, test := range tests {
+		result, ok := toInt(test.input)
+		if result != test.expected || ok != test.ok {
+			t.Errorf("toInt(%v) = (%d, %t), expected (%d, %t)", test.input, result, ok, test.expected, test.ok)
+		}
+	}
+}
+
+// TestVueCompileDiagnostics tests that compiler diagnostics are reported as located build messages
+func TestVueCompileDiagnostics(t *testing.T) {
+	mockConfig := &MockEngineConfig{
+		CompileErrors: []interface{}{
+			map[string]interface{}{
+				"text":     "Error parsing JavaScript expression",
+				"line":     float64(2),
+				"column":   float64(7),
+				"length":   float64(3),
+				"lineText": "<div>{{ a + }}</div>",
+			},
+			map[string]interface{}{"text": "Second error"},
+		},
+		CompileWarnings: []interface{}{
+			map[string]interface{}{"text": "Tip from template", "line": float64(1)},
+		},
+	}
+
+	content := "<template>\n<div>{{ a + }}</div>\n</template>"
+	tmpFile := createTempVueFile(t, content)
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	entryContent := fmt.Sprintf(`import App from '%s';`, filepath.Base(tmpFile))
+	if err := os.WriteFile(entryFile, []byte(entryContent), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(mockConfig)))
+	if err != nil {
+		t.Fatalf("Failed to create JS executor: %v", err)
+	}
+	if err := jsExec.Start(); err != nil {
+		t.Fatalf("Failed to start JS executor: %v", err)
+	}
+	defer jsExec.Stop()
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints: []string{entryFile},
+		Bundle:      true,
+		Write:       false,
+		LogLevel:    api.LogLevelSilent,
+		Plugins:     []api.Plugin{NewPlugin(WithJsExecutor(jsExec))},
+	})
+
+	if len(result.Errors) != 2 {
+		t.Fatalf("Expected 2 build errors, got %d: %v", len(result.Errors), result.Errors)
+	}
+
+	var located *api.Message
+	for i := range result.Errors {
+		if strings.Contains(result.Errors[i].Text, "Error parsing JavaScript expression") {
+			located = &result.Errors[i]
+		}
+	}
+	if located == nil || located.Location == nil {
+		t.Fatalf("Expected located template error, got %v", result.Errors)
+	}
+	if located.Location.Line != 2 || located.Location.Column != 7 {
+		t.Errorf("Expected error at 2:7, got %d:%d", located.Location.Line, located.Location.Column)
+	}
+
+	if len(filterVuePluginWarnings(result.Warnings)) != 1 {
+		t.Errorf("Expected 1 Vue plugin warning, got %v", result.Warnings)
+	}
+}
+
+func TestDiagnosticsWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue": "<template>\n  <div>{{ a + }}</div>\n</template>\n<script>\nexport default {}\n</script>",
+		"main.js": `import App from './App.vue'; console.log(App);`,
+	})
+
+	result := buildWithCompiler(t, tmpDir, "main.js", nil)
+	if len(result.Errors) == 0 {
+		t.Fatal("Expected a template compile error")
+	}
+	location := result.Errors[0].Location
+	if location == nil || location.Line != 2 || !strings.HasSuffix(location.File, "App.vue") {
+		t.Errorf("Expected the error to be located on line 2 of App.vue, got %+v", result.Errors[0])
+	}
+}
@@ -4,6 +4,7 @@
 package qjscompiler
 
 import (
+	"strings"
 	"testing"
 
 	quickjsengine "github.com/buke/js-executor/engines/quickjs-go"
@@ -74,3 +75,22 @@ func TestVueCompilerFactoryEngineCreation(t *testing.T) {
 		engine.Close()
 	}
 }
+
+// TestVueCompilerServices tests that the embedded compiler bundle provides every service the plugin calls,
+// a bundle built from outdated sources fails here instead of at build time
+func TestVueCompilerServices(t *testing.T) {
+	engine, err := NewVueCompilerFactory()()
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	qjsEngine := engine.(*quickjsengine.Engine)
+	for _, service := range []string{"sfc.vue.compileSFC", "sfc.sass.renderSync", "sfc.less.renderSync", "sfc.stylus.renderSync"} {
+		ret := qjsEngine.Ctx.Eval("typeof " + strings.ReplaceAll(service, ".", "?."))
+		if kind := ret.String(); kind != "function" {
+			t.Errorf("Expected the compiler bundle to provide %s, got %s", service, kind)
+		}
+		ret.Free()
+	}
+}
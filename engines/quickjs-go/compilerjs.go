@@ -4,7 +4,9 @@
 package qjscompiler
 
 import (
+	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
 	"sync"
 
 	quickjsengine "github.com/buke/js-executor/engines/quickjs-go"
@@ -21,6 +23,18 @@ var (
 	compilerScriptBytecode []byte
 )
 
+// compilerHash hashes the embedded compiler script once per process.
+var compilerHash = sync.OnceValue(func() string {
+	sum := sha256.Sum256([]byte(compilerScript))
+	return hex.EncodeToString(sum[:])
+})
+
+// CompilerHash returns a hash of the embedded Vue compiler script.
+// It identifies the compiler in the persistent compile cache of the plugin (CompileCacheOptions.CompilerHash).
+func CompilerHash() string {
+	return compilerHash()
+}
+
 // getCompilerScriptBytecode compiles the embedded JS compiler script and caches its bytecode.
 // It uses sync.Once to ensure compilation happens only once per process.
 func getCompilerScriptBytecode(jse *quickjsengine.Engine) []byte {
@@ -0,0 +1,123 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+import { dirname, extname, isAbsolute, join } from 'path-browserify';
+import { SFCBlock, SFCDescriptor } from '@vue/compiler-sfc';
+import { BlockStart, Diagnostic, toDiagnostic } from './diagnostics';
+
+/**
+ * Where the content of a block comes from: the .vue file itself or the
+ * external file referenced by its `src` attribute. Diagnostics are located
+ * against `source`, and reported in `file` when set.
+ */
+export interface BlockOrigin {
+  source: string;
+  start: BlockStart;
+  file?: string;
+}
+
+// Languages implied by the extension of an external block source
+const langByExtension: Record<string, string> = {
+  '.ts': 'ts',
+  '.tsx': 'tsx',
+  '.jsx': 'jsx',
+  '.scss': 'scss',
+  '.sass': 'sass',
+  '.less': 'less',
+  '.styl': 'stylus',
+  '.stylus': 'stylus',
+};
+
+/**
+ * Apply tsconfig path aliases to an import path, mirroring applyPathAlias on the Go side:
+ * exact aliases must match the whole path and take precedence, aliases ending with '*'
+ * match a prefix, the longest prefix first.
+ */
+export function applyPathAlias(pathAlias: Record<string, string> | undefined, path: string): string {
+  const aliases = pathAlias || {};
+  if (!path.endsWith('*') && Object.prototype.hasOwnProperty.call(aliases, path)) {
+    return aliases[path];
+  }
+
+  let matched: string | undefined = undefined;
+  for (const alias of Object.keys(aliases)) {
+    if (alias.endsWith('*') && path.startsWith(alias.slice(0, -1)) && (matched === undefined || alias.length > matched.length)) {
+      matched = alias;
+    }
+  }
+  if (matched === undefined) {
+    return path;
+  }
+  const target = aliases[matched];
+  return (target.endsWith('*') ? target.slice(0, -1) : target) + path.slice(matched.length - 1);
+}
+
+/**
+ * Resolve the `src` attribute of a block relative to the .vue file, honoring path aliases.
+ */
+export function resolveBlockSrc(src: string, filename: string, pathAlias?: Record<string, string>): string {
+  const path = applyPathAlias(pathAlias, src).replace(/\\/g, '/');
+  return isAbsolute(path) ? path : join(dirname(filename), path);
+}
+
+/**
+ * Load the external sources of all blocks with a `src` attribute into the descriptor,
+ * so they go through the same compile pipeline as inline blocks.
+ * Returns the loaded files as watch dependencies, and errors for sources that can't be read.
+ */
+export function loadBlockSources(
+  descriptor: SFCDescriptor,
+  source: string,
+  filename: string,
+  pathAlias?: Record<string, string>
+): { dependencies: string[]; errors: Diagnostic[] } {
+  const dependencies: string[] = [];
+  const errors: Diagnostic[] = [];
+  const blocks: (SFCBlock | null)[] = [descriptor.template, descriptor.script, ...descriptor.styles];
+
+  for (const block of blocks) {
+    if (!block || !block.src) {
+      continue;
+    }
+
+    const path = resolveBlockSrc(block.src, filename, pathAlias);
+    if (!globalThis.compilerFs.fileExists(path)) {
+      errors.push(toDiagnostic(source, `Failed to resolve src "${block.src}": ${path} does not exist`, block.loc.start));
+      continue;
+    }
+
+    block.content = globalThis.compilerFs.readFile(path);
+    block.lang = block.lang || langByExtension[extname(path)];
+    // The block map describes the (empty) inline content, it doesn't apply to the external file
+    block.map = undefined;
+    (block as any).srcPath = path;
+    dependencies.push(path);
+  }
+
+  return { dependencies, errors };
+}
+
+/**
+ * Get the origin of a block content for locating diagnostics.
+ */
+export function blockOrigin(block: SFCBlock, source: string): BlockOrigin {
+  const srcPath: string | undefined = (block as any).srcPath;
+  if (srcPath) {
+    return { source: block.content, start: { offset: 0 }, file: srcPath };
+  }
+  return { source: source, start: block.loc.start };
+}
+
+/**
+ * Convert an error or warning raised while compiling a block into a diagnostic
+ * located in the file the block content comes from.
+ */
+export function blockDiagnostic(block: SFCBlock, source: string, err: any): Diagnostic {
+  const origin = blockOrigin(block, source);
+  const diagnostic = toDiagnostic(origin.source, err, origin.start);
+  // Diagnostics of imported files already carry their own file
+  if (origin.file && !diagnostic.file) {
+    diagnostic.file = origin.file;
+  }
+  return diagnostic;
+}
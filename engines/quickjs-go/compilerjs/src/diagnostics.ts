@@ -0,0 +1,164 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+/**
+ * A compiler message located in the original .vue source.
+ * line is 1-based and column is 0-based, matching esbuild's Location.
+ * file is set when the message belongs to an external block source (`src` attribute).
+ */
+export interface Diagnostic {
+  text: string;
+  file?: string;
+  line?: number;
+  column?: number;
+  length?: number;
+  lineText?: string;
+}
+
+/**
+ * Position of a block content inside the .vue file, as reported by
+ * descriptor blocks (`block.loc.start`).
+ */
+export interface BlockStart {
+  offset: number;
+}
+
+/**
+ * Build a diagnostic from an absolute offset range inside the .vue source.
+ */
+export function diagnosticAt(source: string, text: string, start: number, end?: number): Diagnostic {
+  start = Math.max(0, Math.min(start, source.length));
+  const lineStart = source.lastIndexOf('\n', start - 1) + 1;
+  let lineEnd = source.indexOf('\n', start);
+  if (lineEnd < 0) {
+    lineEnd = source.length;
+  }
+
+  let line = 1;
+  for (let i = 0; i < lineStart; i++) {
+    if (source.charCodeAt(i) === 10) {
+      line++;
+    }
+  }
+
+  // esbuild highlights a single line, so clamp the length to the end of it
+  let length = end !== undefined && end > start ? end - start : 0;
+  length = Math.min(length, lineEnd - start);
+
+  return {
+    text: text,
+    line: line,
+    column: start - lineStart,
+    length: length,
+    lineText: source.slice(lineStart, lineEnd),
+  };
+}
+
+/**
+ * Convert a 1-based line and 0-based column inside a block content into an
+ * absolute offset inside the .vue source.
+ */
+function blockOffset(source: string, block: BlockStart, line: number, column: number): number {
+  let offset = block.offset;
+  for (let l = 1; l < line; l++) {
+    const next = source.indexOf('\n', offset);
+    if (next < 0) {
+      return source.length;
+    }
+    offset = next + 1;
+  }
+  return offset + Math.max(0, column);
+}
+
+function messageOf(err: any): string {
+  if (typeof err === 'string') {
+    return err;
+  }
+  if (err && typeof err.message === 'string') {
+    // Compiler errors may carry a code frame after the first line, esbuild prints its own
+    return err.message.split('\n')[0];
+  }
+  return String(err);
+}
+
+/**
+ * Map an error or warning raised while compiling a block back to the .vue source.
+ *
+ * Handles the location formats produced by the different compilers:
+ *  - @vue/compiler-core errors: `loc.start.offset` / `loc.end.offset` (relative to the block)
+ *  - babel errors: `loc.line` (1-based) / `loc.column` (0-based)
+ *  - less errors: `line` (1-based) / `column` (0-based) with an `extract` of the source lines
+ *  - stylus errors: `lineno` / `column` (both 1-based)
+ *  - postcss errors: `line` / `column` (both 1-based)
+ *  - dart-sass errors: `span.start.line` / `span.start.column` (both 0-based)
+ *
+ * Errors without any location are reported at the start of the block, or
+ * without position when no block is known. When `absolute` is set, compiler-core
+ * offsets are already relative to the whole .vue source.
+ */
+export function toDiagnostic(source: string, err: any, block?: BlockStart, absolute = false): Diagnostic {
+  const text = messageOf(err);
+  if (!err || typeof err !== 'object') {
+    return block ? diagnosticAt(source, text, block.offset) : { text };
+  }
+
+  const base = absolute || !block ? 0 : block.offset;
+
+  if (err.loc && err.loc.start && typeof err.loc.start.offset === 'number') {
+    const start = base + err.loc.start.offset;
+    const end = err.loc.end && typeof err.loc.end.offset === 'number' ? base + err.loc.end.offset : undefined;
+    return diagnosticAt(source, text, start, end);
+  }
+
+  if (!block) {
+    return { text };
+  }
+
+  if (err.loc && typeof err.loc.line === 'number') {
+    return diagnosticAt(source, text, blockOffset(source, block, err.loc.line, err.loc.column || 0));
+  }
+
+  if (typeof err.lineno === 'number') {
+    // Stylus errors: the message starts with "file:line:column" and a code frame, the reason comes last
+    const lines = String(err.message || '').split('\n').filter((l: string) => l.trim() !== '');
+    const reason = lines.length > 0 ? lines[lines.length - 1] : text;
+    const column = Math.max(0, (err.column || 1) - 1);
+    if (err.filename && !err.filename.endsWith('.vue')) {
+      return { text: reason, file: err.filename, line: err.lineno, column: column };
+    }
+    return diagnosticAt(source, reason, blockOffset(source, block, err.lineno, column));
+  }
+
+  if (typeof err.line === 'number' && Array.isArray(err.extract)) {
+    // The failing rule lives in an imported Less file, not in this block
+    if (err.filename && !err.filename.endsWith('.vue')) {
+      return { text, file: err.filename, line: err.line, column: err.column || 0 };
+    }
+    return diagnosticAt(source, text, blockOffset(source, block, err.line, err.column || 0));
+  }
+
+  if (typeof err.line === 'number') {
+    return diagnosticAt(source, text, blockOffset(source, block, err.line, (err.column || 1) - 1));
+  }
+
+  if (err.span && err.span.start && typeof err.span.start.line === 'number') {
+    // The failing rule lives in an imported partial, not in this block
+    if (err.span.url) {
+      const file = String(err.span.url).replace(/^file:\/\//, '');
+      return { text, file: file, line: err.span.start.line + 1, column: err.span.start.column };
+    }
+    const start = blockOffset(source, block, err.span.start.line + 1, err.span.start.column);
+    const length = err.span.text ? err.span.text.length : 0;
+    return diagnosticAt(source, text, start, start + length);
+  }
+
+  return diagnosticAt(source, text, block.offset);
+}
+
+/**
+ * Report an error or warning at the start of a block, for contents whose positions
+ * don't match the .vue source, e.g. templates converted to HTML by a preprocessor.
+ */
+export function blockStartDiagnostic(source: string, err: any, block: BlockStart): Diagnostic {
+  return diagnosticAt(source, messageOf(err), block.offset);
+}
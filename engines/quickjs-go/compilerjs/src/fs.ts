@@ -0,0 +1,43 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+// Minimal `fs` module backed by the compilerFs bridge of the Go host.
+// Bundled in place of Node's fs (see the build script alias) for libraries
+// that read imported files synchronously, like stylus.
+
+function stat(path: string) {
+  if (!globalThis.compilerFs.fileExists(path)) {
+    const err: any = new Error(`ENOENT: no such file or directory, stat '${path}'`);
+    err.code = 'ENOENT';
+    throw err;
+  }
+  // compilerFs can only read files, a path that can't be read is treated as a directory
+  let isFile = true;
+  try {
+    globalThis.compilerFs.readFile(path);
+  } catch (e) {
+    isFile = false;
+  }
+  return {
+    isFile: () => isFile,
+    isDirectory: () => !isFile,
+    isSymbolicLink: () => false,
+  };
+}
+
+export function existsSync(path: string): boolean {
+  return globalThis.compilerFs.fileExists(path);
+}
+
+export function readFileSync(path: string, _options?: any): string {
+  return globalThis.compilerFs.readFile(path);
+}
+
+export function realpathSync(path: string): string {
+  return globalThis.compilerFs.realpath(path);
+}
+
+export const statSync = stat;
+export const lstatSync = stat;
+
+export default { existsSync, readFileSync, realpathSync, statSync, lstatSync };
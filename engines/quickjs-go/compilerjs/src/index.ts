@@ -3,5 +3,7 @@
 
 import * as vue from './vue';
 import * as sass from './sass';
+import * as less from './less';
+import * as stylus from './stylus';
 
-export { vue, sass };
+export { vue, sass, less, stylus };
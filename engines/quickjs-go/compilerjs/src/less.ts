@@ -0,0 +1,131 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+import { join, dirname, isAbsolute, extname } from 'path-browserify';
+import createLess from 'less/lib/less';
+import AbstractFileManager from 'less/lib/less/environment/abstract-file-manager';
+import { SourceMapGenerator } from 'source-map';
+
+function toPosixPath(path: string): string {
+  return path.replace(/\\/g, '/');
+}
+
+function getPossibleFilenames(filename: string, directories: string[]): string[] {
+  const candidates: string[] = [];
+  for (const directory of directories) {
+    const path = directory ? join(directory, filename) : filename;
+    candidates.push(path);
+    // Like lessc, `@import "foo"` also matches foo.less
+    if (extname(path) === '') {
+      candidates.push(path + '.less');
+    }
+  }
+  return candidates;
+}
+
+/**
+ * Less file manager reading imports through the compilerFs bridge of the Go host.
+ * Relative imports are resolved against the importing file, then against `paths`.
+ */
+class CompilerFsFileManager extends AbstractFileManager {
+  supports() {
+    return true;
+  }
+
+  supportsSync() {
+    return true;
+  }
+
+  loadFile(filename: string, currentDirectory: string, options: any, environment: any) {
+    return new Promise((resolve, reject) => {
+      const result = this.loadFileSync(filename, currentDirectory, options, environment);
+      if (result.error) {
+        reject(result.error);
+      } else {
+        resolve(result);
+      }
+    });
+  }
+
+  loadFileSync(filename: string, currentDirectory: string, options: any, _environment?: any): any {
+    filename = toPosixPath(filename);
+    const directories = isAbsolute(filename) ? [''] : [currentDirectory || '', ...(options.paths || [])];
+    const candidates = getPossibleFilenames(filename, directories.map(toPosixPath));
+
+    for (const candidate of candidates) {
+      if (globalThis.compilerFs.fileExists(candidate)) {
+        return {
+          contents: globalThis.compilerFs.readFile(candidate),
+          filename: candidate,
+        };
+      }
+    }
+
+    return {
+      error: {
+        type: 'File',
+        message: `'${filename}' wasn't found. Tried - ${candidates.join(',')}`,
+      },
+    };
+  }
+}
+
+// Minimal environment for the Less core, the browser/node environments rely on XHR or fs
+const environment = {
+  encodeBase64(str: string) {
+    return btoa(unescape(encodeURIComponent(str)));
+  },
+  mimeLookup() {
+    return '';
+  },
+  charsetLookup() {
+    return '';
+  },
+  getSourceMapGenerator() {
+    return SourceMapGenerator;
+  },
+};
+
+const less = createLess(environment, [new CompilerFsFileManager()]);
+
+// The Less API used by @vue/compiler-sfc (`render` with `syncImport: true`)
+export const render = less.render.bind(less);
+export const version = less.version;
+
+/**
+ * Compile a standalone .less file.
+ * Imports are resolved synchronously so the result is available when the call returns.
+ */
+export function renderSync(options: any) {
+  const filename = toPosixPath(options.filename);
+  const sourceMap: boolean = options.sourceMap || false;
+
+  let result: any;
+  let error: any = null;
+  less.render(
+    options.data,
+    {
+      ...options,
+      filename: filename,
+      paths: [dirname(filename), ...(options.paths || [])],
+      syncImport: true,
+      sourceMap: sourceMap ? { outputSourceFiles: true } : undefined,
+    },
+    (err: any, output: any) => {
+      error = err;
+      result = output;
+    }
+  );
+
+  if (error) {
+    // Point at the failing file and line like lessc does, esbuild shows the message as-is
+    const location = error.filename ? ` in ${error.filename} on line ${error.line}, column ${error.column + 1}` : '';
+    throw new Error(`${error.message}${location}`);
+  }
+
+  return {
+    css: result.css,
+    map: result.map || '',
+    imports: result.imports || [],
+  };
+}
@@ -2,12 +2,19 @@
 // SPDX-License-Identifier: Apache-2.0
 
 import * as sass from './sass';
+import * as less from './less';
+import { stylus } from './stylus';
 
 // attention: beaware of cannot import sass module in other place
 //  cause vue will using sassRequire to import sass module,
+//  the same goes for the other style preprocessors (less, stylus)
 export function sassRequire(module: string) {
   if (module === 'sass') {
     return sass;
+  } else if (module === 'less') {
+    return less;
+  } else if (module === 'stylus') {
+    return stylus;
   } else {
     return undefined;
   }
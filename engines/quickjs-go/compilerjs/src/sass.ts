@@ -13,6 +13,8 @@ import { compileString } from './lib/sass';
 import { URL } from './url';
 
 let sasslocation: string | undefined;
+// Files loaded by the running compilation, reported as includedFiles so the host can watch them
+let loadedFiles: string[] = [];
 
 function toPosixPath(path: string): string {
   return path.replace(/\\/g, '/');
@@ -82,6 +84,9 @@ const sassImporter = {
     };
     const ext = extname(path);
     const contents = loadContent(path);
+    if (!loadedFiles.includes(path)) {
+      loadedFiles.push(path);
+    }
 
     return {
       contents: contents,
@@ -90,9 +95,29 @@ const sassImporter = {
   },
 };
 
+// normalizeSourceMap converts a Sass source map to the legacy JSON string format.
+// The entry stylesheet is compiled from a string and gets a data: URL as source,
+// which is replaced by the real file name. Imported files are reported as file: URLs.
+function normalizeSourceMap(sourceMap: any, file: string | undefined): string {
+  if (!sourceMap) {
+    return '';
+  }
+  sourceMap.sources = (sourceMap.sources || []).map((source: string) => {
+    if (source.startsWith('data:')) {
+      return file ? toPosixPath(file) : source;
+    }
+    if (source.startsWith('file://')) {
+      return source.slice('file://'.length);
+    }
+    return source;
+  });
+  return JSON.stringify(sourceMap);
+}
+
 export function renderSync(options: any): LegacyResult {
   try {
     sasslocation = toPosixPath(options.sasslocation);
+    loadedFiles = [];
     const source: string = options.data;
     const sourceMap: boolean = options.sourceMap || false;
     const style: 'expanded' | 'compressed' = options.style || 'compressed';
@@ -100,22 +125,25 @@ export function renderSync(options: any): LegacyResult {
     const result = compileString(source, {
       importer: sassImporter,
       sourceMap: sourceMap,
+      sourceMapIncludeSources: sourceMap,
       style: style,
     }) as CompileResult;
     return {
       css: result.css || '',
-      map: result?.sourceMap || '',
+      // Legacy API consumers (like @vue/compiler-sfc) expect the map as a JSON string
+      map: sourceMap ? (normalizeSourceMap(result.sourceMap, options.file || options.filename) as any) : '',
       stats: {
         entry: '',
         start: 0,
         end: 0,
         duration: 0,
-        includedFiles: [], // Placeholder for included files
+        includedFiles: loadedFiles,
       },
     };
   } catch (e) {
     throw e;
   } finally {
     sasslocation = undefined;
+    loadedFiles = [];
   }
 }
@@ -0,0 +1,50 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+import { dirname, isAbsolute, join } from 'path-browserify';
+import stylus from 'stylus';
+
+function toPosixPath(path: string): string {
+  return path.replace(/\\/g, '/');
+}
+
+// The Stylus API used by @vue/compiler-sfc (`stylus(source, options).render()`)
+export { stylus };
+
+/**
+ * Compile a standalone .styl file.
+ * Imports are resolved relative to the compiled file and to `paths`, and
+ * reported as imports so the caller can watch them, like the Less service.
+ */
+export function renderSync(options: any) {
+  const filename = toPosixPath(options.filename);
+  const location = dirname(filename);
+  const sourceMap: boolean = options.sourceMap || false;
+
+  const ref = stylus(options.data, {
+    ...options,
+    filename: filename,
+    paths: [location, ...(options.paths || [])],
+  });
+  if (sourceMap) {
+    ref.set('sourcemap', { inline: false, comment: false, basePath: location });
+  }
+
+  const css: string = ref.render();
+
+  let map = '';
+  if (sourceMap && ref.sourcemap) {
+    // Sources are relative to basePath, make them absolute like the other preprocessors
+    const sourcemap = { ...ref.sourcemap };
+    sourcemap.sources = (sourcemap.sources || []).map((source: string) =>
+      isAbsolute(source) ? source : join(location, source)
+    );
+    map = JSON.stringify(sourcemap);
+  }
+
+  return {
+    css: css,
+    map: map,
+    imports: ref.deps(),
+  };
+}
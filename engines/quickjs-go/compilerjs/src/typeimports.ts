@@ -0,0 +1,183 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+import { dirname, relative } from 'path-browserify';
+import { babelParse, walk, SFCDescriptor, SFCScriptBlock } from '@vue/compiler-sfc';
+import { SourceMapConsumer, SourceMapGenerator } from 'source-map';
+
+/**
+ * An import source rewritten by rewriteTypeImports. line is 1-based, start and end are
+ * the 0-based columns of the rewritten string literal in the rewritten .vue source.
+ */
+interface TypeImportEdit {
+  line: number;
+  start: number;
+  end: number;
+  original: string;
+  rewritten: string;
+}
+
+/**
+ * The .vue source with rewritten type imports, and the rewritten import sources in source order
+ */
+export interface TypeImports {
+  source: string;
+  edits: TypeImportEdit[];
+}
+
+/**
+ * Returns the string literals of the import sources of TypeScript code, in source order:
+ * imports, re-exports and import types. Code with syntax errors has none, compileScript reports the errors.
+ */
+function importSourceLiterals(code: string, lang: string | undefined): any[] {
+  let ast: any;
+  try {
+    // Same parser plugins as compileScript for TypeScript
+    ast = babelParse(code, {
+      sourceType: 'module',
+      plugins: [
+        'importAttributes',
+        ...(lang === 'tsx' ? ['jsx' as const] : []),
+        'typescript',
+        'explicitResourceManagement',
+        'decorators-legacy',
+      ],
+    });
+  } catch (e) {
+    return [];
+  }
+
+  const literals: any[] = [];
+  walk(ast.program, {
+    enter(node: any) {
+      if (node.type === 'ImportDeclaration' || node.type === 'ExportNamedDeclaration' || node.type === 'ExportAllDeclaration') {
+        literals.push(node.source);
+      } else if (node.type === 'TSImportType') {
+        literals.push(node.argument?.type === 'TSLiteralType' ? node.argument.literal : node.argument);
+      }
+    },
+  });
+  return literals.filter(literal => literal && literal.type === 'StringLiteral').sort((a, b) => a.start - b.start);
+}
+
+/**
+ * Rewrites the non-relative imports resolved by the Go host to paths relative to the component.
+ * The browser build of the compiler only resolves imported types from relative sources,
+ * it rejects other sources before reading any file through the fs option.
+ * Only the import sources of TypeScript script blocks are rewritten, found on their syntax tree.
+ */
+export function rewriteTypeImports(
+  descriptor: SFCDescriptor,
+  source: string,
+  filename: string,
+  imports?: Record<string, string>
+): TypeImports {
+  const edits: TypeImportEdit[] = [];
+  if (!imports || Object.keys(imports).length === 0) {
+    return { source, edits };
+  }
+
+  const blocks = [descriptor.script, descriptor.scriptSetup]
+    .filter((block): block is SFCScriptBlock => !!block && !block.src && (block.lang === 'ts' || block.lang === 'tsx'))
+    .sort((a, b) => a.loc.start.offset - b.loc.start.offset);
+  let rewritten = '';
+  let last = 0;
+  for (const block of blocks) {
+    const offset = block.loc.start.offset;
+    for (const literal of importSourceLiterals(block.content, block.lang)) {
+      const file = imports[literal.value];
+      if (!file) {
+        continue;
+      }
+      let path = relative(dirname(filename), file);
+      if (!path.startsWith('.')) {
+        path = './' + path;
+      }
+
+      const original = source.slice(offset + literal.start, offset + literal.end);
+      const quote = original[0];
+      const replacement = quote + path + quote;
+      rewritten += source.slice(last, offset + literal.start);
+      const lineStart = rewritten.lastIndexOf('\n') + 1;
+      const start = rewritten.length - lineStart;
+      edits.push({
+        line: rewritten.slice(0, lineStart).split('\n').length,
+        start: start,
+        end: start + replacement.length,
+        original: original,
+        rewritten: replacement,
+      });
+      rewritten += replacement;
+      last = offset + literal.end;
+    }
+  }
+  if (edits.length === 0) {
+    return { source, edits };
+  }
+  rewritten += source.slice(last);
+  return { source: rewritten, edits };
+}
+
+/**
+ * Returns the column of the original .vue source for a column of the rewritten source.
+ * Columns inside a rewritten import source map to its start.
+ */
+function originalColumn(edits: TypeImportEdit[], line: number, column: number) {
+  let shift = 0;
+  for (const edit of edits) {
+    if (edit.line !== line || edit.start >= column) {
+      continue;
+    }
+    if (column < edit.end) {
+      return edit.start - shift;
+    }
+    shift += edit.rewritten.length - edit.original.length;
+  }
+  return column - shift;
+}
+
+/**
+ * Restores the original import sources of a script compiled from the source rewritten by rewriteTypeImports.
+ * The import sources of the compiled code are found on its syntax tree, the source map is moved back
+ * to the columns of the original source.
+ */
+export function restoreTypeImports(script: SFCScriptBlock, typeImports: TypeImports, source: string): SFCScriptBlock {
+  const originals = new Map(typeImports.edits.map(edit => [edit.rewritten, edit.original]));
+  let content = '';
+  let last = 0;
+  for (const literal of importSourceLiterals(script.content, script.lang)) {
+    const original = originals.get(script.content.slice(literal.start, literal.end));
+    if (original !== undefined) {
+      content += script.content.slice(last, literal.start) + original;
+      last = literal.end;
+    }
+  }
+  content += script.content.slice(last);
+
+  let map = script.map;
+  if (map) {
+    const consumer = new SourceMapConsumer(map as any);
+    const generator = new SourceMapGenerator({ file: map.file });
+    consumer.eachMapping(mapping => {
+      if (mapping.source === null || mapping.source === undefined) {
+        generator.addMapping({ generated: { line: mapping.generatedLine, column: mapping.generatedColumn } } as any);
+        return;
+      }
+      generator.addMapping({
+        generated: { line: mapping.generatedLine, column: mapping.generatedColumn },
+        original: { line: mapping.originalLine, column: originalColumn(typeImports.edits, mapping.originalLine, mapping.originalColumn) },
+        source: mapping.source,
+        name: mapping.name || undefined,
+      } as any);
+    });
+    for (const sourceFile of consumer.sources) {
+      const sourceContent = consumer.sourceContentFor(sourceFile, true);
+      if (sourceContent !== null) {
+        generator.setSourceContent(sourceFile, sourceContent === typeImports.source ? source : sourceContent);
+      }
+    }
+    map = generator.toJSON() as any;
+  }
+
+  return { ...script, content: content, map: map };
+}
@@ -2,14 +2,17 @@
 // SPDX-License-Identifier: Apache-2.0
 
 import { sassRequire } from './require';
-import { dirname } from 'path-browserify';
+import { dirname, isAbsolute, join } from 'path-browserify';
+import { Diagnostic, blockStartDiagnostic, diagnosticAt, toDiagnostic } from './diagnostics';
+import { blockDiagnostic, loadBlockSources } from './blocksrc';
+import { restoreTypeImports, rewriteTypeImports } from './typeimports';
 
 import {
   parse,
   compileScript,
   compileTemplate,
   compileStyleAsync,
-  SFCDescriptor,
+  SFCScriptBlock,
   SFCTemplateCompileResults,
   SFCStyleCompileResults,
 } from '@vue/compiler-sfc';
@@ -18,6 +21,41 @@ import { createSimpleExpression } from '@vue/compiler-core';
 // Preserve original exports for backward compatibility
 export { parse, compileScript, compileTemplate, compileStyleAsync, createSimpleExpression };
 
+/**
+ * Resolves relative source map sources (PostCSS emits them relative to the file)
+ * to absolute paths so the map stays valid wherever the CSS ends up
+ */
+function resolveMapSources(map: any, filename: string) {
+  if (!map || !map.sources) {
+    return map;
+  }
+  const location = dirname(filename);
+  return {
+    ...map,
+    sources: map.sources.map((source: string) => (isAbsolute(source) ? source : join(location, source))),
+  };
+}
+
+/**
+ * Creates the isCustomElement predicate of the template compiler from the tags matched
+ * by a Go predicate and the patterns of native custom elements.
+ * Throws an error naming the pattern if a pattern isn't a valid regular expression.
+ */
+function createCustomElementMatcher(matcher?: { tags?: string[]; patterns?: string[] }) {
+  if (!matcher) {
+    return undefined;
+  }
+  const tags = new Set(matcher.tags || []);
+  const patterns = (matcher.patterns || []).map(pattern => {
+    try {
+      return new RegExp(pattern);
+    } catch (e) {
+      throw new Error(`Invalid custom element pattern /${pattern}/: ${(e as Error).message}`);
+    }
+  });
+  return (tag: string) => tags.has(tag) || patterns.some(pattern => pattern.test(tag));
+}
+
 /**
  * Unified Vue Single File Component compiler function
  * Completes all compilation steps in a single function to ensure proper CSS variable binding
@@ -31,110 +69,271 @@ export async function compileSFC(
     isProd?: boolean;
     isSSR?: boolean;
     preprocessOptions?: any;
+    customElement?: boolean;
     compilerOptions?: any;
+    isCustomElement?: { tags?: string[]; patterns?: string[] };
+    transformAssetUrls?: false | { tags?: Record<string, string[]>; includeAbsolute?: boolean };
+    modulesOptions?: any;
+    pathAlias?: Record<string, string>;
+    typeResolution?: { imports?: Record<string, string>; globalTypeFiles?: string[] };
+    inlineTemplate?: boolean;
+    templateHtml?: string;
   }
 ) {
+  const errors: Diagnostic[] = [];
+  const warnings: Diagnostic[] = [];
+
   // 1. Parse SFC to get descriptor
-  const { descriptor, errors } = parse(source, {
+  const { descriptor, errors: parseErrors } = parse(source, {
     filename: filename,
   });
 
-  if (errors && errors.length > 0) {
-    throw new Error(`Failed to parse Vue file: ${errors.join(', ')}`);
+  if (parseErrors && parseErrors.length > 0) {
+    // Parse errors are located against the whole file, nothing else can be compiled reliably
+    return {
+      errors: parseErrors.map(e => toDiagnostic(source, e)),
+      warnings: warnings,
+    };
   }
 
+  // Load external block sources (<style src="...">), the files are watched by the caller
+  const { dependencies, errors: srcErrors } = loadBlockSources(descriptor, source, filename, options.pathAlias);
+  if (srcErrors.length > 0) {
+    return {
+      errors: srcErrors,
+      warnings: warnings,
+      dependencies: dependencies,
+    };
+  }
+
+  // Invalid custom element patterns are reported for the whole file, no template can be compiled without them
+  let isCustomElement: ((tag: string) => boolean) | undefined = undefined;
+  try {
+    isCustomElement = createCustomElementMatcher(options.isCustomElement);
+  } catch (e) {
+    return {
+      errors: [toDiagnostic(source, e)],
+      warnings: warnings,
+      dependencies: dependencies,
+    };
+  }
+
+  // Templates written in other languages are converted to HTML by the preprocessor registered on the Go side,
+  // which compiles the component again with the HTML
+  const templateLang = descriptor.template?.lang;
+  const preprocessedTemplate = !!descriptor.template && !!templateLang && templateLang !== 'html';
+  if (descriptor.template && preprocessedTemplate) {
+    if (options.templateHtml === undefined) {
+      const start = diagnosticAt(source, '', descriptor.template.loc.start.offset);
+      return {
+        errors: errors,
+        warnings: warnings,
+        dependencies: dependencies,
+        templatePreprocess: {
+          lang: templateLang,
+          content: descriptor.template.content,
+          src: descriptor.template.src,
+          line: start.line,
+          column: start.column,
+        },
+      };
+    }
+    // Positions in the HTML don't match the template source, diagnostics are reported at the block
+    descriptor.template.content = options.templateHtml;
+    descriptor.template.map = undefined;
+    descriptor.template.ast = undefined;
+  }
+
+  // Template options shared by the template compiler and templates inlined into <script setup>
+  const templateOptions = {
+    ssr: options.isSSR || false,
+    isProd: options.isProd || false,
+    // Asset URLs become imports resolved relative to the .vue file, aliases are resolved by the plugin
+    transformAssetUrls: options.transformAssetUrls,
+    compilerOptions: {
+      inSSR: options.isSSR || false,
+      ...options.compilerOptions,
+      // Native custom elements are not resolved as components
+      ...(isCustomElement ? { isCustomElement: isCustomElement } : {}),
+    },
+  };
+
+  // The render function of <script setup> components is compiled into the setup closure,
+  // no separate template module is emitted
+  const inlineTemplate = !!options.inlineTemplate && !!descriptor.scriptSetup && !!descriptor.template;
+
   // 2. Compile script part
-  const script =
-    descriptor.script || descriptor.scriptSetup
-      ? compileScript(descriptor, {
-          id: id,
-          fs: globalThis.compilerFs,
-          isProd: options.isProd || false,
-          sourceMap: options.sourceMap || false,
-        })
-      : undefined;
+  let script: SFCScriptBlock | undefined = undefined;
+  const scriptBlock = descriptor.scriptSetup || descriptor.script;
+  if (scriptBlock) {
+    try {
+      // Imported types are resolved from a copy of the source with the resolved imports made relative
+      const typeImports = rewriteTypeImports(descriptor, source, filename, options.typeResolution?.imports);
+      let scriptDescriptor = descriptor;
+      if (typeImports.source !== source) {
+        scriptDescriptor = parse(typeImports.source, { filename: filename }).descriptor;
+        loadBlockSources(scriptDescriptor, typeImports.source, filename, options.pathAlias);
+      }
+
+      script = compileScript(scriptDescriptor, {
+        id: id,
+        fs: globalThis.compilerFs,
+        isProd: options.isProd || false,
+        sourceMap: options.sourceMap || false,
+        customElement: options.customElement || false,
+        globalTypeFiles: options.typeResolution?.globalTypeFiles,
+        inlineTemplate: inlineTemplate,
+        templateOptions: inlineTemplate ? templateOptions : undefined,
+      });
+      if (scriptDescriptor !== descriptor) {
+        script = restoreTypeImports(script, typeImports, source);
+      }
+
+      // Files of imported types are watched by the caller
+      for (const dependency of script.deps || []) {
+        if (!dependencies.includes(dependency)) {
+          dependencies.push(dependency);
+        }
+      }
+      for (const w of script.warnings || []) {
+        warnings.push(blockDiagnostic(scriptBlock, source, w));
+      }
+    } catch (e) {
+      errors.push(blockDiagnostic(scriptBlock, source, e));
+    }
+  }
 
   // 3. Compile template part
   let template: (SFCTemplateCompileResults & { scoped: Boolean }) | undefined = undefined;
-  if (descriptor.template) {
+  if (descriptor.template && !inlineTemplate) {
+    const templateBlock = descriptor.template;
     const scoped = descriptor.styles.some(style => style.scoped);
     const templateResult = compileTemplate({
+      ...templateOptions,
       id: 'data-v-' + id,
       source: descriptor.template.content,
       filename: filename,
       scoped: scoped,
       slotted: descriptor.slotted,
-      ssr: options.isSSR || false,
       ssrCssVars: descriptor.cssVars.map(v => `--${v}`),
-      isProd: options.isProd || false,
+      // Map the render code back to the <template> lines of the .vue file
+      inMap: options.sourceMap ? descriptor.template.map : undefined,
       compilerOptions: {
-        inSSR: options.isSSR || false,
         bindingMetadata: script?.bindings,
-        ...options.compilerOptions,
+        ...templateOptions.compilerOptions,
       },
     });
+    const templateDiagnostic = (e: any) =>
+      preprocessedTemplate ? blockStartDiagnostic(source, e, templateBlock.loc.start) : blockDiagnostic(templateBlock, source, e);
+    for (const e of templateResult.errors || []) {
+      errors.push(templateDiagnostic(e));
+    }
+    for (const tip of templateResult.tips || []) {
+      warnings.push(templateDiagnostic(tip));
+    }
+    // Tips are reported with the warnings of the component
+    const { tips, ...compiledTemplate } = templateResult;
     template = {
-      ...templateResult,
+      ...compiledTemplate,
       scoped: scoped,
     };
   }
 
-  if (template && template.errors && template.errors.length > 0) {
-    throw new Error(`Failed to compile template: ${template.errors.join(', ')}`);
-  }
-
   // 4. Compile style parts - using the same compilation context
-  const styles: (SFCStyleCompileResults & { scoped: Boolean })[] = [];
+  const styles: (SFCStyleCompileResults & { scoped: Boolean; module?: string })[] = [];
+  const moduleNames = new Set<string>();
   for (let i = 0; i < descriptor.styles.length; i++) {
     const style = descriptor.styles[i];
-    const location = dirname(filename);
+    // Imports of external style sources are resolved relative to that file
+    const styleFilename: string = (style as any).srcPath || filename;
+    const location = dirname(styleFilename);
+
+    // <style module> is injected as $style, <style module="name"> under the given name
+    const moduleName = style.module ? (typeof style.module === 'string' ? style.module : '$style') : undefined;
+    if (moduleName) {
+      if (moduleNames.has(moduleName)) {
+        warnings.push(
+          toDiagnostic(source, `CSS module name "${moduleName}" is not unique and will override the previous one`, style.loc.start)
+        );
+      }
+      moduleNames.add(moduleName);
+    }
+
     const compiledStyle = await compileStyleAsync({
       id: id,
-      filename: filename,
+      filename: styleFilename,
       source: style.content,
       scoped: !!style.scoped,
+      modules: !!moduleName,
+      modulesOptions: options.modulesOptions,
       preprocessLang: style.lang as any,
       preprocessOptions: Object.assign(
         {
           includePaths: [location],
+          paths: [location],
           location: location,
           sasslocation: location,
-          sourceMap: options.sourceMap || false,
+          // Less expects source map options as an object
+          sourceMap: options.sourceMap ? (style.lang === 'less' ? { outputSourceFiles: true } : true) : false,
           style: 'expanded',
         },
         options.preprocessOptions || {}
       ),
       preprocessCustomRequire: sassRequire,
+      // Chain the block map so the CSS maps back to the <style> lines of the .vue file
+      inMap: options.sourceMap ? style.map : undefined,
     });
 
-    if (compiledStyle.errors && compiledStyle.errors.length > 0) {
-      throw new Error(`Failed to compile styles: ${compiledStyle.errors.join(', ')}`);
+    for (const e of compiledStyle.errors || []) {
+      errors.push(blockDiagnostic(style, source, e));
+    }
+    // Files loaded by the preprocessor (e.g. Sass partials) are watched by the caller
+    for (const dependency of compiledStyle.dependencies || []) {
+      if (!dependencies.includes(dependency)) {
+        dependencies.push(dependency);
+      }
     }
 
-    styles.push({ ...compiledStyle, scoped: !!style.scoped });
+    styles.push({ ...compiledStyle, scoped: !!style.scoped, module: moduleName });
   }
 
-  // 5. Return compilation results
+  // 5. Return compilation results, diagnostics are reported by the caller
   return {
+    errors: errors,
+    warnings: warnings,
+    dependencies: dependencies,
     script: script
       ? {
           lang: script?.lang,
           content: script?.content,
           map: script?.map,
-          warnings: script?.warnings,
           setup: script?.setup,
+          line: scriptBlock?.loc.start.line,
         }
       : undefined,
     template: {
       code: template?.code,
-      tips: template?.tips,
-      errors: template?.errors,
+      // The render code of a preprocessed template maps to the HTML, not to the .vue file
+      map: options.sourceMap && !preprocessedTemplate ? template?.map : undefined,
       scoped: template?.scoped || false,
+      line: descriptor.template?.loc.start.line,
     },
-    styles: styles.map(style => ({
+    styles: styles.map((style, i) => ({
       code: style.code,
+      map: options.sourceMap ? resolveMapSources(style.map, (descriptor.styles[i] as any).srcPath || filename) : undefined,
       scoped: style.scoped,
-      errors: style.errors,
+      module: style.module,
+      modules: style.modules,
+      lang: descriptor.styles[i].lang || 'css',
+      line: descriptor.styles[i].loc.start.line,
+    })),
+    // Custom blocks are processed on the Go side by the registered processors
+    customBlocks: descriptor.customBlocks.map(block => ({
+      type: block.type,
+      content: block.content,
+      attrs: block.attrs,
+      lang: block.lang,
+      line: block.loc.start.line,
     })),
   };
 }
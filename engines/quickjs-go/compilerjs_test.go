@@ -25,6 +25,17 @@ func TestCompilerScriptEmbedded(t *testing.T) {
 	}
 }
 
+// TestCompilerHash tests that the compiler hash is stable and derived from the embedded script
+func TestCompilerHash(t *testing.T) {
+	hash := CompilerHash()
+	if len(hash) != 64 {
+		t.Errorf("Expected SHA-256 hex digest, got %q", hash)
+	}
+	if CompilerHash() != hash {
+		t.Error("Expected stable compiler hash")
+	}
+}
+
 // TestGetCompilerScriptBytecode tests the getCompilerScriptBytecode function
 func TestGetCompilerScriptBytecode(t *testing.T) {
 	runtime := quickjs.NewRuntime()
@@ -0,0 +1,718 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/cespare/xxhash"
+	"github.com/evanw/esbuild/pkg/api"
+	"golang.org/x/net/html"
+)
+
+// Types of HMR messages pushed to the browser
+const (
+	HmrUpdateMessage     = "update"      // Components are rerendered or reloaded in place
+	HmrFullReloadMessage = "full-reload" // The page must be reloaded
+	HmrErrorMessage      = "error"       // The rebuild failed, the page keeps running the previous build
+	HmrCssUpdateMessage  = "css-update"  // Stylesheets are swapped without running any JavaScript
+)
+
+// Kinds of component updates
+const (
+	HmrRerender = "rerender" // Only the template changed, the render function is replaced
+	HmrReload   = "reload"   // The script changed, the component is remounted with its new definition
+)
+
+// HmrUpdate describes the update of a single component.
+type HmrUpdate struct {
+	Id   string `json:"id"`   // HMR ID of the component, equal to its scope ID
+	File string `json:"file"` // Path of the .vue file
+	Kind string `json:"kind"` // HmrRerender or HmrReload
+	Code string `json:"code"` // Script applying the update in the browser
+}
+
+// HmrStylesheet describes a CSS output file that changed in a rebuild.
+// The names differ when the output names contain a content hash.
+type HmrStylesheet struct {
+	From string `json:"from"` // File name of the stylesheet loaded by the page
+	To   string `json:"to"`   // File name of the rebuilt stylesheet
+}
+
+// HmrMessage is a message pushed to the browser after a rebuild.
+type HmrMessage struct {
+	Type        string          `json:"type"`                  // HmrUpdateMessage, HmrFullReloadMessage, HmrErrorMessage or HmrCssUpdateMessage
+	Updates     []HmrUpdate     `json:"updates,omitempty"`     // Component updates of an HmrUpdateMessage
+	Stylesheets []HmrStylesheet `json:"stylesheets,omitempty"` // Changed stylesheets of an HmrCssUpdateMessage
+	Errors      []string        `json:"errors,omitempty"`      // Build errors of an HmrErrorMessage
+}
+
+// hmrClientScript is the browser client, it connects to the event stream next to its own URL
+// and applies the updates with the Vue HMR runtime.
+const hmrClientScript = `(function () {
+  var script = document.currentScript;
+  var url = script && script.src ? script.src.replace(/\/client\.js(\?.*)?$/, '') : '/__vue_hmr';
+  var source = new EventSource(url);
+  source.onmessage = function (event) {
+    var message = JSON.parse(event.data);
+    if (message.type === 'full-reload') {
+      location.reload();
+    } else if (message.type === 'error') {
+      (message.errors || []).forEach(function (error) {
+        console.error('[vue-hmr] ' + error);
+      });
+    } else if (message.type === 'update') {
+      // Updates are loaded as modules from blob URLs and applied in order
+      var applied = Promise.resolve();
+      (message.updates || []).forEach(function (update) {
+        applied = applied.then(function () {
+          var moduleUrl = URL.createObjectURL(new Blob([update.code], { type: 'text/javascript' }));
+          return import(moduleUrl).then(function () {
+            console.log('[vue-hmr] ' + update.kind + ' ' + update.file);
+          }, function (e) {
+            console.error('[vue-hmr] failed to update ' + update.file + ', reloading', e);
+            throw e;
+          }).finally(function () {
+            URL.revokeObjectURL(moduleUrl);
+          });
+        });
+      });
+      applied.catch(function () {
+        location.reload();
+      });
+    } else if (message.type === 'css-update') {
+      (message.stylesheets || []).forEach(function (sheet) {
+        var links = document.querySelectorAll('link[rel="stylesheet"]');
+        Array.prototype.forEach.call(links, function (link) {
+          var url = new URL(link.href, location.href);
+          var name = url.pathname.slice(url.pathname.lastIndexOf('/') + 1);
+          if (name !== sheet.from) return;
+          url.pathname = url.pathname.slice(0, url.pathname.length - name.length) + sheet.to;
+          url.searchParams.set('t', Date.now());
+          // The previous stylesheet is kept until the new one is loaded to avoid a flash of unstyled content
+          var next = link.cloneNode();
+          next.href = url.toString();
+          next.onload = function () { link.remove(); };
+          link.parentNode.insertBefore(next, link.nextSibling);
+          console.log('[vue-hmr] css-update ' + sheet.to);
+        });
+      });
+    }
+  };
+})();
+`
+
+// HmrServer pushes component updates to the browser over Server-Sent Events.
+// It serves the event stream at its mount path and the browser client at "<path>/client.js".
+//
+// Example usage:
+//
+//	hmr := NewHmrServer("http://localhost:8081/__vue_hmr", "http://localhost:8080")
+//	go http.ListenAndServe(":8081", hmr)
+//	plugin := NewPlugin(WithJsExecutor(jsExec), WithHmr(hmr))
+type HmrServer struct {
+	url            string
+	allowedOrigins map[string]bool
+	mu             sync.Mutex
+	clients        map[chan []byte]struct{}
+}
+
+// NewHmrServer creates an HMR server reachable by the browser at the given URL.
+// The URL is used to inject the client script into the generated index.html.
+// Pages served from the allowed origins (e.g. "http://localhost:8080", the dev server of the bundle)
+// may connect to the event stream, other cross-origin pages are refused by the browser.
+func NewHmrServer(url string, allowedOrigins ...string) *HmrServer {
+	server := &HmrServer{
+		url:            strings.TrimSuffix(url, "/"),
+		allowedOrigins: make(map[string]bool, len(allowedOrigins)),
+		clients:        make(map[chan []byte]struct{}),
+	}
+	for _, origin := range allowedOrigins {
+		server.allowedOrigins[strings.TrimSuffix(origin, "/")] = true
+	}
+	return server
+}
+
+// ClientURL returns the URL of the browser client script.
+func (s *HmrServer) ClientURL() string {
+	return s.url + "/client.js"
+}
+
+// ServeHTTP serves the browser client and the event stream.
+func (s *HmrServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The dev server serving the bundle usually runs on another port, only its origin may connect
+	w.Header().Add("Vary", "Origin")
+	if origin := r.Header.Get("Origin"); s.allowedOrigins[origin] {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if strings.HasSuffix(r.URL.Path, "/client.js") {
+		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, _ = w.Write([]byte(hmrClientScript))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	client := make(chan []byte, 16)
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			_, _ = fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case data := <-client:
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// Broadcast sends a message to all connected browsers.
+// Browsers that don't keep up with the messages miss them rather than blocking the build.
+func (s *HmrServer) Broadcast(message HmrMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode HMR message: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- data:
+		default:
+		}
+	}
+	return nil
+}
+
+// injectHmrClient adds the HMR client script to the <head> of the index.html document.
+func injectHmrClient(doc *html.Node, server *HmrServer) {
+	head := htmlquery.FindOne(doc, "//head")
+	if head == nil {
+		return
+	}
+
+	head.AppendChild(&html.Node{
+		Type: html.ElementNode,
+		Data: "script",
+		Attr: []html.Attribute{{Key: "src", Val: server.ClientURL()}},
+	})
+	head.AppendChild(&html.Node{Type: html.TextNode, Data: "\n"})
+}
+
+// hmrSnapshot holds hashes of the compiled parts of a component, used to classify its changes.
+type hmrSnapshot struct {
+	id       string // HMR ID of the component
+	script   uint64 // Hash of the compiled script, custom blocks and the style flags used by the entry
+	template uint64 // Hash of the compiled template
+	styles   uint64 // Hash of the compiled CSS
+}
+
+// newHmrSnapshot hashes the compiled parts of a component.
+func newHmrSnapshot(id string, script, template map[string]interface{}, styles, customBlocks []map[string]interface{}) hmrSnapshot {
+	hashParts := func(parts ...interface{}) uint64 {
+		data, _ := json.Marshal(parts)
+		return xxhash.Sum64(data)
+	}
+
+	// Scoped flags and CSS Modules mappings are part of the component code, only the CSS
+	// itself can be swapped without running JavaScript
+	entryParts := make([]interface{}, 0, len(styles))
+	cssParts := make([]interface{}, 0, len(styles))
+	for _, style := range styles {
+		entryParts = append(entryParts, style["scoped"], style["module"], style["modules"])
+		cssParts = append(cssParts, style["code"])
+	}
+
+	snapshot := hmrSnapshot{id: id, styles: hashParts(cssParts...)}
+	if script != nil {
+		snapshot.script = hashParts(script["content"], customBlocks, entryParts)
+	} else {
+		snapshot.script = hashParts(nil, customBlocks, entryParts)
+	}
+	if template != nil {
+		snapshot.template = hashParts(template["code"])
+	}
+	return snapshot
+}
+
+// fileStamp identifies the version of a file that is not a component.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// hmrOutput identifies the version of a JavaScript or CSS output file.
+type hmrOutput struct {
+	path string // Output path as listed in the metafile
+	hash uint64 // Hash of the output contents
+}
+
+// hmrChanges is the result of the comparison of two consecutive builds.
+type hmrChanges struct {
+	components  map[string]string // Changed components by update kind
+	stylesheets []HmrStylesheet   // Changed CSS outputs
+	fullReload  bool              // Whether the page must be reloaded instead
+}
+
+// hmrTracker compares the components, inputs and outputs of consecutive builds.
+type hmrTracker struct {
+	mu          sync.Mutex
+	previous    map[string]hmrSnapshot // Components of the last successful build
+	current     map[string]hmrSnapshot // Components of the running build
+	inputs      map[string]fileStamp   // Other inputs of the last successful build
+	outputs     map[string]hmrOutput   // Outputs of the last successful build
+	hasPrevious bool                   // Whether a build succeeded before
+}
+
+// newHmrTracker creates a tracker without a previous build.
+func newHmrTracker() *hmrTracker {
+	return &hmrTracker{current: make(map[string]hmrSnapshot)}
+}
+
+// begin starts tracking a new build.
+func (t *hmrTracker) begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current = make(map[string]hmrSnapshot)
+}
+
+// record stores the snapshot of a component compiled in the running build.
+func (t *hmrTracker) record(filePath string, snapshot hmrSnapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current[filePath] = snapshot
+}
+
+// idOf returns the HMR ID of a component of the running build.
+func (t *hmrTracker) idOf(filePath string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snapshot, ok := t.current[filePath]
+	return snapshot.id, ok
+}
+
+// importsUnchanged reports whether the modules bundled into an update, other than components,
+// are unchanged since the last successful build. The update runs its own copy of these modules,
+// which only matches the modules of the running page while they are unchanged.
+func (t *hmrTracker) importsUnchanged(metafile string, buildOptions *api.BuildOptions) bool {
+	inputs := collectInputStamps(metafile, buildOptions)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for path, stamp := range inputs {
+		if before, ok := t.inputs[path]; !ok || !before.modTime.Equal(stamp.modTime) || before.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
+
+// commit finishes a successful build and compares it with the previous one.
+// Changes of component styles and stylesheet inputs are applied by swapping the changed
+// CSS outputs. A full reload is required when other inputs changed, or when JavaScript
+// outputs changed without a component update explaining it.
+func (t *hmrTracker) commit(inputs map[string]fileStamp, outputs map[string]hmrOutput) hmrChanges {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hadPrevious, previous, previousInputs, previousOutputs := t.hasPrevious, t.previous, t.inputs, t.outputs
+	t.previous, t.inputs, t.outputs, t.hasPrevious = t.current, inputs, outputs, true
+	changes := hmrChanges{components: make(map[string]string)}
+	if !hadPrevious {
+		return changes
+	}
+
+	// Modules other than components and stylesheets can't be replaced in a bundle
+	for path, stamp := range inputs {
+		if previousStamp, ok := previousInputs[path]; (!ok || !previousStamp.modTime.Equal(stamp.modTime) || previousStamp.size != stamp.size) && !isStylesheetPath(path) {
+			changes.fullReload = true
+		}
+	}
+	for path := range previousInputs {
+		if _, ok := inputs[path]; !ok && !isStylesheetPath(path) {
+			changes.fullReload = true
+		}
+	}
+
+	for path, snapshot := range t.current {
+		before, ok := previous[path]
+		if !ok {
+			// New components are picked up through the update of their importer
+			continue
+		}
+		switch {
+		case before.id != snapshot.id:
+			// The running component can't be found under its new ID
+			changes.fullReload = true
+		case before.script != snapshot.script:
+			changes.components[path] = HmrReload
+		case before.template != snapshot.template:
+			changes.components[path] = HmrRerender
+		}
+		// Style changes are picked up through the CSS outputs
+	}
+
+	scriptChanged := false
+	for key, output := range outputs {
+		before, ok := previousOutputs[key]
+		if ok && before.hash == output.hash {
+			continue
+		}
+		if !strings.HasPrefix(key, "css:") {
+			scriptChanged = true
+			continue
+		}
+		if !ok {
+			// The page has no stylesheet to swap
+			changes.fullReload = true
+			continue
+		}
+		changes.stylesheets = append(changes.stylesheets, HmrStylesheet{From: path.Base(before.path), To: path.Base(output.path)})
+	}
+	for key := range previousOutputs {
+		if _, ok := outputs[key]; !ok {
+			if strings.HasPrefix(key, "css:") {
+				changes.fullReload = true
+			}
+			scriptChanged = true
+		}
+	}
+	if scriptChanged && len(changes.components) == 0 {
+		// e.g. CSS Modules of a stylesheet changed their exports
+		changes.fullReload = true
+	}
+	sort.Slice(changes.stylesheets, func(i, j int) bool { return changes.stylesheets[i].To < changes.stylesheets[j].To })
+	return changes
+}
+
+// isStylesheetPath reports whether the input is a stylesheet, whose changes only affect CSS outputs.
+func isStylesheetPath(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".css", ".scss", ".sass", ".less", ".styl", ".stylus":
+		return true
+	}
+	return false
+}
+
+// collectInputStamps stats the inputs of a build that are not handled by the Vue plugin,
+// based on the metafile of the build.
+func collectInputStamps(metafile string, buildOptions *api.BuildOptions) map[string]fileStamp {
+	var meta struct {
+		Inputs map[string]interface{} `json:"inputs"`
+	}
+	stamps := make(map[string]fileStamp)
+	if err := json.Unmarshal([]byte(metafile), &meta); err != nil {
+		return stamps
+	}
+	for input := range meta.Inputs {
+		// Component parts are tracked by their snapshots, other namespaces may point to files
+		path := input
+		if namespace, rest, found := strings.Cut(input, ":"); found && !filepath.IsAbs(input) {
+			if strings.HasPrefix(namespace, "sfc-") || namespace == "vue-hmr-shim" {
+				continue
+			}
+			path = rest
+		}
+		path = strings.SplitN(path, "?", 2)[0]
+		if strings.HasSuffix(path, ".vue") {
+			continue
+		}
+
+		// Virtual modules can't be stat'ed and are skipped
+		path = resolveMetafilePath(path, buildOptions)
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+	return stamps
+}
+
+// resolveMetafilePath converts a metafile path, which is relative to the working directory, to an absolute path.
+func resolveMetafilePath(input string, buildOptions *api.BuildOptions) string {
+	if filepath.IsAbs(input) {
+		return input
+	}
+	root := buildOptions.AbsWorkingDir
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	return filepath.Join(root, filepath.FromSlash(input))
+}
+
+// collectOutputs hashes the JavaScript and CSS outputs of a build, based on the metafile of the build.
+// Outputs are keyed by their entry point, as their names change when they contain a content hash.
+// CSS bundles are keyed by the entry point of the JavaScript output they belong to.
+func collectOutputs(result *api.BuildResult, buildOptions *api.BuildOptions) map[string]hmrOutput {
+	var meta struct {
+		Outputs map[string]struct {
+			EntryPoint string `json:"entryPoint"`
+			CssBundle  string `json:"cssBundle"`
+		} `json:"outputs"`
+	}
+	outputs := make(map[string]hmrOutput)
+	if err := json.Unmarshal([]byte(result.Metafile), &meta); err != nil {
+		return outputs
+	}
+
+	// Output files are only returned when they are not written to disk
+	contents := make(map[string][]byte, len(result.OutputFiles))
+	for _, file := range result.OutputFiles {
+		contents[file.Path] = file.Contents
+	}
+
+	cssEntryPoints := make(map[string]string)
+	for _, output := range meta.Outputs {
+		if output.CssBundle != "" && output.EntryPoint != "" {
+			cssEntryPoints[output.CssBundle] = output.EntryPoint
+		}
+	}
+
+	for outputPath, output := range meta.Outputs {
+		kind := "js"
+		switch path.Ext(outputPath) {
+		case ".css":
+			kind = "css"
+		case ".js", ".mjs", ".cjs":
+		default:
+			continue
+		}
+		key := outputPath
+		if entryPoint, ok := cssEntryPoints[outputPath]; ok {
+			key = entryPoint
+		} else if output.EntryPoint != "" {
+			key = output.EntryPoint
+		}
+
+		absPath := resolveMetafilePath(outputPath, buildOptions)
+		data, ok := contents[absPath]
+		if !ok {
+			var err error
+			if data, err = os.ReadFile(absPath); err != nil {
+				continue
+			}
+		}
+		outputs[kind+":"+key] = hmrOutput{path: outputPath, hash: xxhash.Sum64(data)}
+	}
+	return outputs
+}
+
+// setupHmrHandler tracks the components of every build and pushes their updates to the browser
+// once a rebuild of the esbuild context finishes.
+func setupHmrHandler(opts *Options, build *api.PluginBuild) {
+	build.OnStart(func() (api.OnStartResult, error) {
+		opts.hmrTracker.begin()
+		return api.OnStartResult{}, nil
+	})
+
+	build.OnEnd(func(result *api.BuildResult) (api.OnEndResult, error) {
+		// Keep the previous build as reference, the browser keeps running it
+		if len(result.Errors) > 0 {
+			errors := make([]string, len(result.Errors))
+			for i, msg := range result.Errors {
+				errors[i] = msg.Text
+				if msg.Location != nil {
+					errors[i] = fmt.Sprintf("%s:%d:%d: %s", msg.Location.File, msg.Location.Line, msg.Location.Column, msg.Text)
+				}
+			}
+			return api.OnEndResult{}, opts.hmr.Broadcast(HmrMessage{Type: HmrErrorMessage, Errors: errors})
+		}
+
+		changes := opts.hmrTracker.commit(collectInputStamps(result.Metafile, build.InitialOptions), collectOutputs(result, build.InitialOptions))
+		if changes.fullReload {
+			return api.OnEndResult{}, opts.hmr.Broadcast(HmrMessage{Type: HmrFullReloadMessage})
+		}
+
+		// Apply updates in a stable order
+		paths := make([]string, 0, len(changes.components))
+		for path := range changes.components {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		updates, ok, err := buildHmrUpdates(opts, build.InitialOptions, paths, changes.components)
+		if err != nil {
+			opts.logger.Error("Failed to build HMR updates", "error", err, "files", paths)
+		}
+		if err != nil || !ok {
+			return api.OnEndResult{}, opts.hmr.Broadcast(HmrMessage{Type: HmrFullReloadMessage})
+		}
+		if len(updates) > 0 {
+			if err := opts.hmr.Broadcast(HmrMessage{Type: HmrUpdateMessage, Updates: updates}); err != nil {
+				return api.OnEndResult{}, err
+			}
+		}
+
+		// Stylesheets are swapped without running any JavaScript
+		if len(changes.stylesheets) > 0 {
+			return api.OnEndResult{}, opts.hmr.Broadcast(HmrMessage{Type: HmrCssUpdateMessage, Stylesheets: changes.stylesheets})
+		}
+		return api.OnEndResult{}, nil
+	})
+}
+
+// hmrUpdateNamespace holds the entry modules of HMR update builds, one per updated component
+const hmrUpdateNamespace = "vue-hmr-update"
+
+// buildHmrUpdates bundles the code applying the updates of the changed components in the browser,
+// in a single build with one entry point per component. The updates share vue and the other
+// components with the running page, other modules imported by the component scripts are bundled
+// again. Reload updates are only possible if these modules are unchanged since the running build;
+// false is returned if the page must be reloaded instead.
+func buildHmrUpdates(opts *Options, buildOptions *api.BuildOptions, paths []string, kinds map[string]string) ([]HmrUpdate, bool, error) {
+	if len(paths) == 0 {
+		return nil, true, nil
+	}
+
+	entries := make(map[string]string, len(paths))
+	entryPoints := make([]api.EntryPoint, len(paths))
+	updates := make([]HmrUpdate, len(paths))
+	reload := false
+	for i, filePath := range paths {
+		id, _ := opts.hmrTracker.idOf(filePath)
+		kind := kinds[filePath]
+		updates[i] = HmrUpdate{Id: id, File: filePath, Kind: kind}
+		reload = reload || kind == HmrReload
+
+		importPath := toPosixPath(filePath)
+		var entry string
+		if kind == HmrRerender {
+			entry = fmt.Sprintf("import { render } from %q;\n__VUE_HMR_RUNTIME__.rerender(%q, render);\n", importPath, id)
+		} else {
+			entry = fmt.Sprintf("import component from %q;\n__VUE_HMR_RUNTIME__.reload(%q, component);\n", importPath, id)
+		}
+		entries[filePath] = "if (typeof __VUE_HMR_RUNTIME__ === 'undefined') throw new Error('Vue HMR runtime is not available, use a development build of Vue');\n" + entry
+		entryPoints[i] = api.EntryPoint{InputPath: hmrUpdateNamespace + ":" + filePath, OutputPath: fmt.Sprintf("%s-%d", hmrUpdateNamespace, i)}
+	}
+
+	// The update build runs the same handlers without the build hooks of the main build
+	updateOpts := *opts
+	updateOpts.hmr = nil
+	updateOpts.hmrUpdates = kinds
+	updateOpts.scopeIds = newScopeIdRegistry()
+	updateOpts.onStartProcessors = nil
+	updateOpts.onEndProcessors = nil
+	updateOpts.onDisposeProcessors = nil
+
+	updateOptions := *buildOptions
+	updateOptions.EntryPoints = nil
+	updateOptions.EntryPointsAdvanced = entryPoints
+	updateOptions.Stdin = nil
+	updateOptions.Bundle = true
+	updateOptions.Write = false
+	updateOptions.Format = api.FormatIIFE
+	updateOptions.Splitting = false
+	updateOptions.Sourcemap = api.SourceMapNone
+	updateOptions.Metafile = true
+	updateOptions.OutExtension = nil
+	if updateOptions.Outdir == "" {
+		updateOptions.Outdir = filepath.Dir(updateOptions.Outfile)
+	}
+	updateOptions.Outfile = ""
+	updateOptions.Plugins = []api.Plugin{hmrShimPlugin(opts, entries), newPlugin(&updateOpts)}
+
+	result := api.Build(updateOptions)
+	if len(result.Errors) > 0 {
+		return nil, false, fmt.Errorf("failed to build HMR update: %s", result.Errors[0].Text)
+	}
+
+	if reload && !opts.hmrTracker.importsUnchanged(result.Metafile, &updateOptions) {
+		return nil, false, nil
+	}
+
+	for i := range updates {
+		name := fmt.Sprintf("%s-%d.js", hmrUpdateNamespace, i)
+		for _, file := range result.OutputFiles {
+			if filepath.Base(file.Path) == name {
+				updates[i].Code = string(file.Contents)
+			}
+		}
+		if updates[i].Code == "" {
+			return nil, false, fmt.Errorf("HMR update build produced no JavaScript for %s", updates[i].File)
+		}
+	}
+	return updates, true, nil
+}
+
+// hmrShimPlugin loads the entry modules of the updated components, and maps vue and the other
+// components of the running page to the instances registered on globalThis by the main bundle,
+// so updates don't create a second copy of them.
+func hmrShimPlugin(opts *Options, entries map[string]string) api.Plugin {
+	return api.Plugin{
+		Name: "vue-hmr-shim",
+		Setup: func(build api.PluginBuild) {
+			build.OnResolve(api.OnResolveOptions{Filter: `^` + hmrUpdateNamespace + `:`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+				return api.OnResolveResult{Path: strings.TrimPrefix(args.Path, hmrUpdateNamespace+":"), Namespace: hmrUpdateNamespace}, nil
+			})
+
+			build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: hmrUpdateNamespace}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				contents := entries[args.Path]
+				return api.OnLoadResult{Contents: &contents, ResolveDir: filepath.Dir(args.Path), Loader: api.LoaderJS}, nil
+			})
+
+			build.OnResolve(api.OnResolveOptions{Filter: `^vue$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+				return api.OnResolveResult{Path: "vue", Namespace: "vue-hmr-shim"}, nil
+			})
+
+			build.OnResolve(api.OnResolveOptions{Filter: `\.vue$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+				path, err := opts.pathAlias.apply(args.Path)
+				if err != nil {
+					return api.OnResolveResult{}, err
+				}
+				if !filepath.IsAbs(path) {
+					path = filepath.Clean(filepath.Join(args.ResolveDir, path))
+				}
+
+				// The updated components themselves are compiled again
+				id, ok := opts.hmrTracker.idOf(path)
+				if _, updated := entries[path]; updated || !ok {
+					return api.OnResolveResult{}, nil
+				}
+				return api.OnResolveResult{Path: id, Namespace: "vue-hmr-shim", PluginData: "component"}, nil
+			})
+
+			build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: "vue-hmr-shim"}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+				contents := "module.exports = globalThis.__VUE_HMR_VUE__;"
+				if args.PluginData == "component" {
+					contents = fmt.Sprintf("module.exports = { __esModule: true, default: globalThis.__VUE_HMR_COMPONENTS__[%q] };", args.Path)
+				}
+				return api.OnLoadResult{Contents: &contents, Loader: api.LoaderJS}, nil
+			})
+		},
+	}
+}
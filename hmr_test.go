@@ -0,0 +1,575 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/evanw/esbuild/pkg/api"
+	"golang.org/x/net/html"
+)
+
+// subscribeHmr registers a client channel on the server, as an event stream connection would.
+func subscribeHmr(server *HmrServer) chan []byte {
+	client := make(chan []byte, 16)
+	server.mu.Lock()
+	server.clients[client] = struct{}{}
+	server.mu.Unlock()
+	return client
+}
+
+// receiveHmr waits for the next message pushed to the client channel.
+func receiveHmr(t *testing.T, client chan []byte) HmrMessage {
+	t.Helper()
+	select {
+	case data := <-client:
+		var message HmrMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("Failed to decode HMR message: %v", err)
+		}
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for HMR message")
+		return HmrMessage{}
+	}
+}
+
+// Unit tests
+
+func TestHmrServer(t *testing.T) {
+	server := NewHmrServer("http://localhost:8081/__vue_hmr/", "http://localhost:8080/")
+	if server.ClientURL() != "http://localhost:8081/__vue_hmr/client.js" {
+		t.Errorf("Unexpected client URL: %s", server.ClientURL())
+	}
+
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	t.Run("client_script", func(t *testing.T) {
+		resp, err := http.Get(httpServer.URL + "/__vue_hmr/client.js")
+		if err != nil {
+			t.Fatalf("Failed to get client script: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(resp.Header.Get("Content-Type"), "javascript") || !strings.Contains(string(body), "EventSource") {
+			t.Errorf("Unexpected client script response: %s %s", resp.Header.Get("Content-Type"), body)
+		}
+		// Updates are loaded as modules, pages don't need to allow eval
+		if !strings.Contains(string(body), "import(moduleUrl)") || strings.Contains(string(body), "new Function") {
+			t.Errorf("Expected updates to be imported from blob URLs, got %s", body)
+		}
+	})
+
+	t.Run("event_stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/__vue_hmr", nil)
+		req.Header.Set("Origin", "http://localhost:8080")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to connect to event stream: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.Header.Get("Content-Type") != "text/event-stream" || resp.Header.Get("Access-Control-Allow-Origin") != "http://localhost:8080" {
+			t.Errorf("Unexpected event stream headers: %v", resp.Header)
+		}
+
+		reader := bufio.NewReader(resp.Body)
+		if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+			t.Fatalf("Expected connection comment, got %q", line)
+		}
+		_, _ = reader.ReadString('\n')
+
+		if err := server.Broadcast(HmrMessage{Type: HmrFullReloadMessage}); err != nil {
+			t.Fatalf("Failed to broadcast: %v", err)
+		}
+		line, _ := reader.ReadString('\n')
+		if line != "data: {\"type\":\"full-reload\"}\n" {
+			t.Errorf("Unexpected event: %q", line)
+		}
+	})
+
+	t.Run("other_origin", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, httpServer.URL+"/__vue_hmr/client.js", nil)
+		req.Header.Set("Origin", "http://example.com")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to get client script: %v", err)
+		}
+		defer resp.Body.Close()
+		if origin := resp.Header.Get("Access-Control-Allow-Origin"); origin != "" {
+			t.Errorf("Expected other origins not to be allowed, got %q", origin)
+		}
+	})
+}
+
+func TestInjectHmrClient(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader("<html><head><title>App</title></head><body></body></html>"))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	injectHmrClient(doc, NewHmrServer("http://localhost:8081/__vue_hmr"))
+
+	script := htmlquery.FindOne(doc, "//head/script")
+	if script == nil || htmlquery.SelectAttr(script, "src") != "http://localhost:8081/__vue_hmr/client.js" {
+		t.Errorf("Expected HMR client script in head, got: %s", htmlquery.OutputHTML(doc, true))
+	}
+}
+
+func TestHmrTrackerCommit(t *testing.T) {
+	script := map[string]interface{}{"content": "export default {}"}
+	template := map[string]interface{}{"code": "function render() {}"}
+	styles := []map[string]interface{}{{"code": ".a {}"}}
+	base := newHmrSnapshot("a1", script, template, styles, nil)
+
+	tests := []struct {
+		name         string
+		snapshot     hmrSnapshot
+		inputs       map[string]fileStamp
+		expectKind   string
+		expectReload bool
+	}{
+		{"unchanged", base, nil, "", false},
+		{"template_changed", newHmrSnapshot("a1", script, map[string]interface{}{"code": "function render() { return 1 }"}, styles, nil), nil, HmrRerender, false},
+		{"script_changed", newHmrSnapshot("a1", map[string]interface{}{"content": "export default { a: 1 }"}, template, styles, nil), nil, HmrReload, false},
+		{"custom_block_changed", newHmrSnapshot("a1", script, template, styles, []map[string]interface{}{{"content": "{}"}}), nil, HmrReload, false},
+		{"style_changed", newHmrSnapshot("a1", script, template, []map[string]interface{}{{"code": ".b {}"}}, nil), nil, "", false},
+		{"style_scoped", newHmrSnapshot("a1", script, template, []map[string]interface{}{{"code": ".a {}", "scoped": true}}, nil), nil, HmrReload, false},
+		{"id_changed", newHmrSnapshot("b2", script, template, styles, nil), nil, "", true},
+		{"input_changed", base, map[string]fileStamp{"/src/util.ts": {size: 1}}, "", true},
+		{"stylesheet_changed", base, map[string]fileStamp{"/src/theme.scss": {size: 1}}, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracker := newHmrTracker()
+			tracker.begin()
+			tracker.record("/src/App.vue", base)
+			if changes := tracker.commit(nil, nil); len(changes.components) != 0 || changes.fullReload {
+				t.Fatalf("Expected no updates for the first build, got %+v", changes)
+			}
+
+			tracker.begin()
+			tracker.record("/src/App.vue", test.snapshot)
+			tracker.record("/src/New.vue", base)
+			changes := tracker.commit(test.inputs, nil)
+			if changes.fullReload != test.expectReload {
+				t.Errorf("Expected full reload %v, got %v", test.expectReload, changes.fullReload)
+			}
+			if changes.components["/src/App.vue"] != test.expectKind {
+				t.Errorf("Expected update kind %q, got %q", test.expectKind, changes.components["/src/App.vue"])
+			}
+			if _, ok := changes.components["/src/New.vue"]; ok {
+				t.Error("Expected new components not to be updated")
+			}
+			if id, ok := tracker.idOf("/src/App.vue"); !ok || id != test.snapshot.id {
+				t.Errorf("Expected ID %s, got %s", test.snapshot.id, id)
+			}
+		})
+	}
+}
+
+func TestHmrTrackerCommitOutputs(t *testing.T) {
+	base := map[string]hmrOutput{
+		"js:src/main.ts":  {path: "dist/main.js", hash: 1},
+		"css:src/main.ts": {path: "dist/main.css", hash: 2},
+	}
+
+	tests := []struct {
+		name              string
+		outputs           map[string]hmrOutput
+		component         bool
+		expectStylesheets []HmrStylesheet
+		expectReload      bool
+	}{
+		{"unchanged", base, false, nil, false},
+		{"css_changed", map[string]hmrOutput{
+			"js:src/main.ts":  {path: "dist/main.js", hash: 1},
+			"css:src/main.ts": {path: "dist/main.css", hash: 3},
+		}, false, []HmrStylesheet{{From: "main.css", To: "main.css"}}, false},
+		{"css_renamed", map[string]hmrOutput{
+			"js:src/main.ts":  {path: "dist/main.js", hash: 1},
+			"css:src/main.ts": {path: "dist/main-NEW.css", hash: 3},
+		}, false, []HmrStylesheet{{From: "main.css", To: "main-NEW.css"}}, false},
+		{"css_added", map[string]hmrOutput{
+			"js:src/main.ts":   {path: "dist/main.js", hash: 1},
+			"css:src/main.ts":  {path: "dist/main.css", hash: 2},
+			"css:src/other.ts": {path: "dist/other.css", hash: 4},
+		}, false, nil, true},
+		{"js_changed", map[string]hmrOutput{
+			"js:src/main.ts":  {path: "dist/main.js", hash: 5},
+			"css:src/main.ts": {path: "dist/main.css", hash: 2},
+		}, false, nil, true},
+		{"js_changed_by_component", map[string]hmrOutput{
+			"js:src/main.ts":  {path: "dist/main.js", hash: 5},
+			"css:src/main.ts": {path: "dist/main.css", hash: 2},
+		}, true, nil, false},
+	}
+
+	script := map[string]interface{}{"content": "export default {}"}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracker := newHmrTracker()
+			tracker.begin()
+			tracker.record("/src/App.vue", newHmrSnapshot("a1", script, nil, nil, nil))
+			tracker.commit(nil, base)
+
+			tracker.begin()
+			if test.component {
+				tracker.record("/src/App.vue", newHmrSnapshot("a1", map[string]interface{}{"content": "export default { a: 1 }"}, nil, nil, nil))
+			} else {
+				tracker.record("/src/App.vue", newHmrSnapshot("a1", script, nil, nil, nil))
+			}
+			changes := tracker.commit(nil, test.outputs)
+			if changes.fullReload != test.expectReload {
+				t.Errorf("Expected full reload %v, got %v", test.expectReload, changes.fullReload)
+			}
+			if fmt.Sprint(changes.stylesheets) != fmt.Sprint(test.expectStylesheets) {
+				t.Errorf("Expected stylesheets %v, got %v", test.expectStylesheets, changes.stylesheets)
+			}
+		})
+	}
+}
+
+func TestCollectOutputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "dist"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "dist", "main-ABC.css"), []byte(".a {}"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	result := &api.BuildResult{
+		Metafile: `{"outputs": {
+			"dist/main.js": {"entryPoint": "src/main.ts", "cssBundle": "dist/main-ABC.css"},
+			"dist/main-ABC.css": {},
+			"dist/main.js.map": {},
+			"dist/chunk.js": {}
+		}}`,
+		OutputFiles: []api.OutputFile{
+			{Path: filepath.Join(tmpDir, "dist", "main.js"), Contents: []byte("console.log(1)")},
+			{Path: filepath.Join(tmpDir, "dist", "chunk.js"), Contents: []byte("export {}")},
+		},
+	}
+	outputs := collectOutputs(result, &api.BuildOptions{AbsWorkingDir: tmpDir})
+	if len(outputs) != 3 {
+		t.Fatalf("Expected 3 outputs, got %v", outputs)
+	}
+	if output, ok := outputs["js:src/main.ts"]; !ok || output.path != "dist/main.js" {
+		t.Errorf("Expected JavaScript output keyed by its entry point, got %v", outputs)
+	}
+	if output, ok := outputs["css:src/main.ts"]; !ok || output.path != "dist/main-ABC.css" {
+		t.Errorf("Expected CSS bundle keyed by its entry point, got %v", outputs)
+	}
+	if _, ok := outputs["js:dist/chunk.js"]; !ok {
+		t.Errorf("Expected chunk keyed by its path, got %v", outputs)
+	}
+
+	if outputs := collectOutputs(&api.BuildResult{Metafile: "{invalid"}, &api.BuildOptions{}); len(outputs) != 0 {
+		t.Errorf("Expected no outputs for an invalid metafile, got %v", outputs)
+	}
+}
+
+func TestCollectInputStamps(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"main.ts", "theme.scss"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	metafile := fmt.Sprintf(`{"inputs": {
+		"main.ts": {},
+		"App.vue": {},
+		"sfc-script:%[1]s/App.vue?type=script": {},
+		"sass-loader:%[1]s/theme.scss": {},
+		"vue-hmr-shim:vue": {},
+		"virtual:missing.ts": {}
+	}}`, filepath.ToSlash(tmpDir))
+
+	stamps := collectInputStamps(metafile, &api.BuildOptions{AbsWorkingDir: tmpDir})
+	if len(stamps) != 2 {
+		t.Fatalf("Expected 2 tracked inputs, got %v", stamps)
+	}
+	for _, name := range []string{"main.ts", "theme.scss"} {
+		if stamp, ok := stamps[filepath.Join(tmpDir, name)]; !ok || stamp.size != 1 {
+			t.Errorf("Expected %s to be tracked, got %v", name, stamps)
+		}
+	}
+
+	if stamps := collectInputStamps("{invalid", &api.BuildOptions{}); len(stamps) != 0 {
+		t.Errorf("Expected no inputs for an invalid metafile, got %v", stamps)
+	}
+}
+
+func TestImportsUnchanged(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"store.ts", "utils.ts"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	options := &api.BuildOptions{AbsWorkingDir: tmpDir}
+	tracker := newHmrTracker()
+	tracker.commit(collectInputStamps(`{"inputs": {"store.ts": {}, "App.vue": {}}}`, options), nil)
+
+	tests := []struct {
+		name     string
+		inputs   string
+		expected bool
+	}{
+		{"component_only", `"<stdin>": {}, "App.vue": {}, "sfc-script:App.vue?type=script": {}, "vue-hmr-shim:vue": {}`, true},
+		{"unchanged_module", `"<stdin>": {}, "App.vue": {}, "store.ts": {}`, true},
+		{"new_module", `"<stdin>": {}, "App.vue": {}, "utils.ts": {}`, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metafile := `{"inputs": {` + test.inputs + `}}`
+			if result := tracker.importsUnchanged(metafile, options); result != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, result)
+			}
+		})
+	}
+
+	// Modules changed since the running build are bundled with a different state
+	if err := os.WriteFile(filepath.Join(tmpDir, "store.ts"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to update store.ts: %v", err)
+	}
+	if tracker.importsUnchanged(`{"inputs": {"store.ts": {}}}`, options) {
+		t.Error("Expected a changed module to require a full reload")
+	}
+}
+
+// Integration tests
+
+func TestHmrRebuild(t *testing.T) {
+	tmpDir := t.TempDir()
+	vueFile := filepath.Join(tmpDir, "App.vue")
+	if err := os.WriteFile(vueFile, []byte(`<template><div>Test</div></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	// vue is shared with the running page, it only needs to resolve in the main build
+	if err := os.MkdirAll(filepath.Join(tmpDir, "node_modules", "vue"), 0755); err != nil {
+		t.Fatalf("Failed to create vue package: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "node_modules", "vue", "index.js"), []byte("export const h = () => null;"), 0644); err != nil {
+		t.Fatalf("Failed to create vue package: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	mockConfig := &MockEngineConfig{
+		Script:   &MockScriptConfig{Content: "export default { name: 'App' }", Lang: "js"},
+		Template: &MockTemplateConfig{Code: "export function render() { return 'v1'; }"},
+		Styles:   []*MockStyleConfig{{Code: ".app { color: red; }", Scoped: false}},
+	}
+	jsExec := newMockExecutor(t, mockConfig)
+	server := NewHmrServer("http://localhost:8081/__vue_hmr")
+	client := subscribeHmr(server)
+
+	ctx, ctxErr := api.Context(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		Outdir:        filepath.Join(tmpDir, "dist"),
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithHmr(server))},
+	})
+	if ctxErr != nil {
+		t.Fatalf("Failed to create build context: %v", ctxErr)
+	}
+	defer ctx.Dispose()
+
+	result := ctx.Rebuild()
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if !strings.Contains(string(result.OutputFiles[0].Contents), "__hmrId") {
+		t.Error("Expected component to be registered with the HMR runtime")
+	}
+	select {
+	case data := <-client:
+		t.Fatalf("Expected no message for the first build, got %s", data)
+	default:
+	}
+
+	// Compiled components are cached until their source changes
+	editComponent := func(t *testing.T, version string) {
+		t.Helper()
+		if err := os.WriteFile(vueFile, []byte(`<template><div>`+version+`</div></template>`), 0644); err != nil {
+			t.Fatalf("Failed to update Vue file: %v", err)
+		}
+	}
+
+	t.Run("rerender", func(t *testing.T) {
+		editComponent(t, "rerender")
+		mockConfig.Template.Code = "export function render() { return 'v2'; }"
+		if result := ctx.Rebuild(); len(result.Errors) > 0 {
+			t.Fatalf("Expected successful rebuild, got errors: %v", result.Errors)
+		}
+		message := receiveHmr(t, client)
+		if message.Type != HmrUpdateMessage || len(message.Updates) != 1 {
+			t.Fatalf("Expected one update, got %+v", message)
+		}
+		update := message.Updates[0]
+		if update.Kind != HmrRerender || update.File != vueFile || !strings.Contains(update.Code, "__VUE_HMR_RUNTIME__.rerender") || !strings.Contains(update.Code, "v2") {
+			t.Errorf("Unexpected rerender update: %+v", update)
+		}
+		if strings.Contains(update.Code, "name: 'App'") {
+			t.Error("Expected rerender update not to contain the component script")
+		}
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		editComponent(t, "reload")
+		mockConfig.Script.Content = "export default { name: 'AppV2' }"
+		if result := ctx.Rebuild(); len(result.Errors) > 0 {
+			t.Fatalf("Expected successful rebuild, got errors: %v", result.Errors)
+		}
+		message := receiveHmr(t, client)
+		if message.Type != HmrUpdateMessage || len(message.Updates) != 1 {
+			t.Fatalf("Expected one update, got %+v", message)
+		}
+		update := message.Updates[0]
+		if update.Kind != HmrReload || !strings.Contains(update.Code, "__VUE_HMR_RUNTIME__.reload") || !strings.Contains(update.Code, "AppV2") {
+			t.Errorf("Unexpected reload update: %+v", update)
+		}
+		if !strings.Contains(update.Code, "__VUE_HMR_VUE__") {
+			t.Error("Expected update to use the vue instance of the running page")
+		}
+	})
+
+	t.Run("css_update", func(t *testing.T) {
+		editComponent(t, "css_update")
+		mockConfig.Styles[0].Code = ".app { color: blue; }"
+		if result := ctx.Rebuild(); len(result.Errors) > 0 {
+			t.Fatalf("Expected successful rebuild, got errors: %v", result.Errors)
+		}
+		message := receiveHmr(t, client)
+		if message.Type != HmrCssUpdateMessage || len(message.Stylesheets) != 1 {
+			t.Fatalf("Expected one stylesheet update, got %+v", message)
+		}
+		if sheet := message.Stylesheets[0]; sheet.From != "entry.css" || sheet.To != "entry.css" {
+			t.Errorf("Unexpected stylesheet update: %+v", sheet)
+		}
+		select {
+		case data := <-client:
+			t.Errorf("Expected no other message, got %s", data)
+		default:
+		}
+	})
+
+	t.Run("full_reload", func(t *testing.T) {
+		time.Sleep(10 * time.Millisecond)
+		if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log('changed', App);`), 0644); err != nil {
+			t.Fatalf("Failed to update entry file: %v", err)
+		}
+		if result := ctx.Rebuild(); len(result.Errors) > 0 {
+			t.Fatalf("Expected successful rebuild, got errors: %v", result.Errors)
+		}
+		if message := receiveHmr(t, client); message.Type != HmrFullReloadMessage {
+			t.Errorf("Expected full reload, got %+v", message)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		editComponent(t, "error")
+		mockConfig.CompileErrors = []interface{}{map[string]interface{}{"text": "Unexpected token", "line": 1, "column": 0}}
+		defer func() { mockConfig.CompileErrors = nil }()
+		if result := ctx.Rebuild(); len(result.Errors) == 0 {
+			t.Fatal("Expected build errors")
+		}
+		message := receiveHmr(t, client)
+		if message.Type != HmrErrorMessage || len(message.Errors) == 0 || !strings.Contains(message.Errors[0], "Unexpected token") {
+			t.Errorf("Expected error message, got %+v", message)
+		}
+	})
+}
+
+func TestHmrRebuildMultipleComponents(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := []string{filepath.Join(tmpDir, "App.vue"), filepath.Join(tmpDir, "Other.vue")}
+	writeComponents := func(t *testing.T, version string) {
+		t.Helper()
+		for _, file := range files {
+			if err := os.WriteFile(file, []byte(`<template><div>`+version+`</div></template>`), 0644); err != nil {
+				t.Fatalf("Failed to write Vue file: %v", err)
+			}
+		}
+	}
+	writeComponents(t, "v1")
+	if err := os.MkdirAll(filepath.Join(tmpDir, "node_modules", "vue"), 0755); err != nil {
+		t.Fatalf("Failed to create vue package: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "node_modules", "vue", "index.js"), []byte("export const h = () => null;"), 0644); err != nil {
+		t.Fatalf("Failed to create vue package: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; import Other from './Other.vue'; console.log(App, Other);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	mockConfig := &MockEngineConfig{
+		Script:   &MockScriptConfig{Content: "export default { name: 'Component' }", Lang: "js"},
+		Template: &MockTemplateConfig{Code: "export function render() { return 'v1'; }"},
+	}
+	server := NewHmrServer("http://localhost:8081/__vue_hmr")
+	client := subscribeHmr(server)
+	ctx, ctxErr := api.Context(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		Outfile:       filepath.Join(tmpDir, "dist", "bundle.js"),
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(newMockExecutor(t, mockConfig)), WithHmr(server))},
+	})
+	if ctxErr != nil {
+		t.Fatalf("Failed to create build context: %v", ctxErr)
+	}
+	defer ctx.Dispose()
+	if result := ctx.Rebuild(); len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	writeComponents(t, "v2")
+	mockConfig.Template.Code = "export function render() { return 'v2'; }"
+	if result := ctx.Rebuild(); len(result.Errors) > 0 {
+		t.Fatalf("Expected successful rebuild, got errors: %v", result.Errors)
+	}
+
+	// Both updates come from a single update build, each one applies its own component
+	message := receiveHmr(t, client)
+	if message.Type != HmrUpdateMessage || len(message.Updates) != 2 {
+		t.Fatalf("Expected two updates, got %+v", message)
+	}
+	for i, file := range files {
+		update := message.Updates[i]
+		if update.File != file || update.Kind != HmrRerender || !strings.Contains(update.Code, "v2") {
+			t.Errorf("Unexpected update of %s: %+v", file, update)
+		}
+		if !strings.Contains(update.Code, fmt.Sprintf("__VUE_HMR_RUNTIME__.rerender(%q", update.Id)) {
+			t.Errorf("Expected update of %s to rerender %s, got %s", file, update.Id, update.Code)
+		}
+	}
+	if message.Updates[0].Id == message.Updates[1].Id {
+		t.Errorf("Expected distinct HMR IDs, got %q", message.Updates[0].Id)
+	}
+}
@@ -177,6 +177,11 @@ func setupHtmlHandler(opts *Options, build *api.PluginBuild) {
 			}
 		}
 
+		// Load the HMR client, which applies component updates pushed after rebuilds
+		if opts.hmr != nil {
+			injectHmrClient(doc, opts.hmr)
+		}
+
 		// Render and save the modified HTML document
 		var buf bytes.Buffer
 		err = html.Render(&buf, doc)
@@ -189,6 +194,14 @@ func setupHtmlHandler(opts *Options, build *api.PluginBuild) {
 			return api.OnEndResult{}, err
 		}
 
+		// Render the configured routes to static pages, using the processed HTML as the shell
+		if opts.prerenderOptions != nil {
+			if err := prerenderRoutes(opts, buf.Bytes(), build.InitialOptions); err != nil {
+				opts.logger.Error("Failed to prerender routes", "error", err)
+				return api.OnEndResult{}, err
+			}
+		}
+
 		return api.OnEndResult{}, nil
 	})
 }
@@ -0,0 +1,30 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// lessLoader compiles .less files with the Less service of the Vue compiler.
+var lessLoader = stylesheetLoader{
+	name:      "Less",
+	filter:    `\.less$`,
+	namespace: "less-loader",
+	service:   "sfc.less.renderSync",
+}
+
+// setupLessHandler registers handlers for Less files (.less).
+// It sets up the complete processing pipeline including path resolution and compilation.
+func setupLessHandler(opts *Options, build *api.PluginBuild) {
+	setupStylesheetHandler(opts, build, lessLoader)
+}
+
+// compileLess compiles Less to CSS using the Vue compiler via the JS executor.
+// The style preprocessor options are passed to Less, allowing settings like modifyVars,
+// globalVars or javascriptEnabled.
+func compileLess(filePath, source string, sourceMap bool, preprocessorOptions map[string]any, jsExecutor *jsexecutor.JsExecutor) (*stylesheetResult, error) {
+	return compileStylesheet(lessLoader, filePath, source, sourceMap, preprocessorOptions, jsExecutor)
+}
@@ -0,0 +1,219 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestCompileLess(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockConfig  *MockEngineConfig
+		sourceMap   bool
+		expectError string
+		expectCSS   string
+		expectMap   string
+	}{
+		{
+			name: "successful_compilation",
+			mockConfig: &MockEngineConfig{ServiceResponses: map[string]interface{}{
+				"sfc.less.renderSync": map[string]interface{}{
+					"css":     ".button { color: #333; }",
+					"map":     `{"version":3}`,
+					"imports": []interface{}{"/test/theme.less"},
+				},
+			}},
+			expectCSS: ".button { color: #333; }",
+		},
+		{
+			name: "with_sourcemap",
+			mockConfig: &MockEngineConfig{ServiceResponses: map[string]interface{}{
+				"sfc.less.renderSync": map[string]interface{}{"css": ".a {}", "map": `{"version":3}`},
+			}},
+			sourceMap: true,
+			expectCSS: ".a {}",
+			expectMap: `{"version":3}`,
+		},
+		{
+			name:        "service_error",
+			mockConfig:  &MockEngineConfig{ExecuteError: fmt.Errorf("variable @primary is undefined")},
+			expectError: "less compilation service failed",
+		},
+		{
+			name:        "invalid_result",
+			mockConfig:  &MockEngineConfig{InvalidResult: true},
+			expectError: "invalid response from less compilation service",
+		},
+		{
+			name: "missing_css",
+			mockConfig: &MockEngineConfig{ServiceResponses: map[string]interface{}{
+				"sfc.less.renderSync": map[string]interface{}{"map": ""},
+			}},
+			expectError: "failed to extract CSS",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsExec := newMockExecutor(t, test.mockConfig)
+			result, err := compileLess("/test/app.less", "@primary: #333;", test.sourceMap, nil, jsExec)
+
+			if test.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("Expected error containing '%s', got: %v", test.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if result.css != test.expectCSS || result.sourceMap != test.expectMap {
+				t.Errorf("Unexpected result: %+v", result)
+			}
+		})
+	}
+}
+
+func TestCompileLessOptions(t *testing.T) {
+	var lessOptions map[string]interface{}
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		ServiceResponses: map[string]interface{}{
+			"sfc.less.renderSync": map[string]interface{}{"css": ".a {}"},
+		},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			lessOptions = req.Args[0].(map[string]interface{})
+		},
+	})
+
+	_, err := compileLess("/test/app.less", "@a: 1;", false, map[string]any{
+		"javascriptEnabled": true,
+		"filename":          "/should/not/override.less",
+	}, jsExec)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if lessOptions["javascriptEnabled"] != true {
+		t.Errorf("Expected preprocessor options to be passed, got %v", lessOptions)
+	}
+	if lessOptions["filename"] != "/test/app.less" || lessOptions["data"] != "@a: 1;" {
+		t.Errorf("Expected file name and source to take precedence, got %v", lessOptions)
+	}
+}
+
+// Integration tests
+
+func TestLessCompilationWithMockEngine(t *testing.T) {
+	tmpFile := createTempSassFile(t, "@import 'theme';\n.button { color: @primary; }", "less")
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import '%s';`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{ServiceResponses: map[string]interface{}{
+		"sfc.less.renderSync": map[string]interface{}{
+			"css":     ".button { color: #1890ff; }",
+			"imports": []interface{}{filepath.Join(filepath.Dir(tmpFile), "theme.less")},
+		},
+	}})
+
+	result := buildSassTest(t, entryFile, jsExec, func(options *api.BuildOptions) {
+		options.Sourcemap = api.SourceMapExternal
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	foundCSS := false
+	for _, file := range result.OutputFiles {
+		if strings.HasSuffix(file.Path, ".css") {
+			foundCSS = true
+			if !strings.Contains(string(file.Contents), ".button") {
+				t.Errorf("Expected CSS to contain '.button', got: %s", file.Contents)
+			}
+		}
+	}
+	if !foundCSS {
+		t.Error("Expected CSS output file, got none")
+	}
+}
+
+func TestLessLoadHandlerErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	entryFile := filepath.Join(tmpDir, "entry.js")
+
+	t.Run("read_error", func(t *testing.T) {
+		if err := os.WriteFile(entryFile, []byte(`import './missing.less';`), 0644); err != nil {
+			t.Fatalf("Failed to create entry file: %v", err)
+		}
+		jsExec := newMockExecutor(t, &MockEngineConfig{})
+
+		result := buildSassTest(t, entryFile, jsExec)
+		if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "failed to read less file") {
+			t.Errorf("Expected read error, got: %v", result.Errors)
+		}
+	})
+
+	t.Run("compile_error", func(t *testing.T) {
+		lessFile := filepath.Join(tmpDir, "broken.less")
+		if err := os.WriteFile(lessFile, []byte(".a { color: @undefined; }"), 0644); err != nil {
+			t.Fatalf("Failed to create less file: %v", err)
+		}
+		if err := os.WriteFile(entryFile, []byte(`import './broken.less';`), 0644); err != nil {
+			t.Fatalf("Failed to create entry file: %v", err)
+		}
+		jsExec := newMockExecutor(t, &MockEngineConfig{ExecuteError: fmt.Errorf("variable @undefined is undefined")})
+
+		result := buildSassTest(t, entryFile, jsExec)
+		if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "variable @undefined is undefined") {
+			t.Errorf("Expected compile error, got: %v", result.Errors)
+		}
+	})
+
+	t.Run("resolve_error", func(t *testing.T) {
+		if err := os.WriteFile(entryFile, []byte(`import './broken.less';`), 0644); err != nil {
+			t.Fatalf("Failed to create entry file: %v", err)
+		}
+		jsExec := newMockExecutor(t, &MockEngineConfig{})
+
+		result := buildSassTest(t, entryFile, jsExec, func(options *api.BuildOptions) {
+			options.TsconfigRaw = `{invalid json}`
+		})
+		if len(result.Errors) == 0 {
+			t.Error("Expected build errors due to invalid tsconfig, got none")
+		}
+	})
+}
+
+func TestLessWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"theme.less": "@primary: #1890ff;",
+		"app.less":   "@import 'theme';\n.button { color: @primary; }",
+		"App.vue":    "<template><div class=\"card\"></div></template>\n<style lang=\"less\">\n@import './theme.less';\n.card { border-color: @primary; }\n</style>",
+		"main.js":    `import App from './App.vue'; import './app.less'; console.log(App);`,
+	})
+
+	result := buildWithCompiler(t, tmpDir, "main.js", nil)
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	css := outputFile(result, ".css")
+	for _, expected := range []string{".button", ".card", "#1890ff"} {
+		if !strings.Contains(css, expected) {
+			t.Errorf("Expected the CSS to contain %s, got:\n%s", expected, css)
+		}
+	}
+}
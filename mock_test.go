@@ -5,8 +5,13 @@ package vueplugin
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
 
+	qjscompiler "github.com/buke/esbuild-plugin-vue-go/engines/quickjs-go"
 	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
 )
 
 // MockEngineConfig defines the configuration for a mock engine
@@ -25,6 +30,17 @@ type MockEngineConfig struct {
 	Template *MockTemplateConfig
 	// Styles configuration (for Vue SFC)
 	Styles []*MockStyleConfig
+	// Compiler diagnostics returned by compileSFC (errors and warnings)
+	CompileErrors   []interface{}
+	CompileWarnings []interface{}
+	// Custom blocks returned by compileSFC
+	CustomBlocks []interface{}
+	// Watch dependencies returned by compileSFC
+	Dependencies []interface{}
+	// Template conversion requested by compileSFC until the preprocessed HTML is passed as templateHtml
+	TemplatePreprocess map[string]interface{}
+	// Called with every request before it is handled, used to inspect service arguments
+	OnRequest func(req *jsexecutor.JsRequest)
 	// Sass configuration (for Sass compilation)
 	Sass *MockSassConfig
 	// Service-specific responses for different services
@@ -49,12 +65,14 @@ type MockScriptConfig struct {
 type MockTemplateConfig struct {
 	// Template code
 	Code string
-	// Template tips
-	Tips []interface{}
 	// Template errors
 	Errors []interface{}
 	// Template scoped flag
 	Scoped bool
+	// Template sourcemap, omitted if nil
+	Map interface{}
+	// Template inlined into <script setup>, the code is omitted
+	Inline bool
 }
 
 // MockStyleConfig defines style-specific configuration
@@ -65,6 +83,10 @@ type MockStyleConfig struct {
 	Scoped interface{}
 	// Style errors
 	Errors []interface{}
+	// Style sourcemap, omitted if nil
+	Map interface{}
+	// Style language, omitted if empty
+	Lang string
 }
 
 // MockSassConfig defines Sass compilation configuration
@@ -98,6 +120,11 @@ func (e *MockEngine) Reload(scripts []*jsexecutor.InitScript) error { return nil
 func (e *MockEngine) Close() error                                  { return nil }
 
 func (e *MockEngine) Execute(req *jsexecutor.JsRequest) (*jsexecutor.JsResponse, error) {
+	// Let the test inspect the request if configured
+	if e.config.OnRequest != nil {
+		e.config.OnRequest(req)
+	}
+
 	// Return execute error if configured
 	if e.config.ExecuteError != nil {
 		return nil, e.config.ExecuteError
@@ -150,6 +177,20 @@ func (e *MockEngine) Execute(req *jsexecutor.JsRequest) (*jsexecutor.JsResponse,
 func (e *MockEngine) handleVueCompileSFC(req *jsexecutor.JsRequest) (*jsexecutor.JsResponse, error) {
 	result := map[string]interface{}{}
 
+	// Request the template conversion, like the compiler does for templates in other languages
+	if e.config.TemplatePreprocess != nil {
+		if options, ok := req.Args[3].(map[string]interface{}); ok && options["templateHtml"] == nil {
+			result["templatePreprocess"] = e.config.TemplatePreprocess
+			if e.config.Dependencies != nil {
+				result["dependencies"] = e.config.Dependencies
+			}
+			return &jsexecutor.JsResponse{
+				Id:     req.Id,
+				Result: result,
+			}, nil
+		}
+	}
+
 	// Add script
 	if e.config.Script != nil {
 		if e.config.Script.Content == nil {
@@ -184,12 +225,15 @@ func (e *MockEngine) handleVueCompileSFC(req *jsexecutor.JsRequest) (*jsexecutor
 			"code":   e.config.Template.Code,
 			"scoped": e.config.Template.Scoped,
 		}
-		if e.config.Template.Tips != nil {
-			templateMap["tips"] = e.config.Template.Tips
+		if e.config.Template.Inline {
+			delete(templateMap, "code")
 		}
 		if e.config.Template.Errors != nil {
 			templateMap["errors"] = e.config.Template.Errors
 		}
+		if e.config.Template.Map != nil {
+			templateMap["map"] = e.config.Template.Map
+		}
 		result["template"] = templateMap
 	} else {
 		// Default template
@@ -210,6 +254,12 @@ func (e *MockEngine) handleVueCompileSFC(req *jsexecutor.JsRequest) (*jsexecutor
 			if style.Errors != nil {
 				styleMap["errors"] = style.Errors
 			}
+			if style.Map != nil {
+				styleMap["map"] = style.Map
+			}
+			if style.Lang != "" {
+				styleMap["lang"] = style.Lang
+			}
 			styles[i] = styleMap
 		}
 		result["styles"] = styles
@@ -218,6 +268,20 @@ func (e *MockEngine) handleVueCompileSFC(req *jsexecutor.JsRequest) (*jsexecutor
 		result["styles"] = []interface{}{}
 	}
 
+	// Add compiler diagnostics
+	if e.config.CompileErrors != nil {
+		result["errors"] = e.config.CompileErrors
+	}
+	if e.config.CompileWarnings != nil {
+		result["warnings"] = e.config.CompileWarnings
+	}
+	if e.config.CustomBlocks != nil {
+		result["customBlocks"] = e.config.CustomBlocks
+	}
+	if e.config.Dependencies != nil {
+		result["dependencies"] = e.config.Dependencies
+	}
+
 	return &jsexecutor.JsResponse{
 		Id:     req.Id,
 		Result: result,
@@ -520,3 +584,77 @@ func CreateMockEngineConfigWithNilStyleScoped() *MockEngineConfig {
 		},
 	}
 }
+
+// newMockExecutor creates and starts a JS executor with the given mock config
+func newMockExecutor(t *testing.T, mockConfig *MockEngineConfig) *jsexecutor.JsExecutor {
+	t.Helper()
+	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(mockConfig)))
+	if err != nil {
+		t.Fatalf("Failed to create JS executor: %v", err)
+	}
+	if err := jsExec.Start(); err != nil {
+		t.Fatalf("Failed to start JS executor: %v", err)
+	}
+	t.Cleanup(func() { jsExec.Stop() })
+	return jsExec
+}
+
+// newCompilerExecutor creates and starts a JS executor with the embedded Vue compiler bundle,
+// tests using it check the behavior of the shipped compiler instead of a mock
+func newCompilerExecutor(t *testing.T) *jsexecutor.JsExecutor {
+	t.Helper()
+	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(qjscompiler.NewVueCompilerFactory()))
+	if err != nil {
+		t.Fatalf("Failed to create JS executor: %v", err)
+	}
+	if err := jsExec.Start(); err != nil {
+		t.Fatalf("Failed to start JS executor: %v", err)
+	}
+	t.Cleanup(func() { jsExec.Stop() })
+	return jsExec
+}
+
+// writeProjectFiles writes the files of a test project, paths are relative to dir
+func writeProjectFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for file, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", file, err)
+		}
+	}
+}
+
+// buildWithCompiler bundles an entry of a test project with the Vue plugin and the embedded compiler.
+// The vue package is external since test projects have no node_modules, configure adjusts the build options.
+func buildWithCompiler(t *testing.T, dir, entry string, configure func(*api.BuildOptions), options ...OptionFunc) api.BuildResult {
+	t.Helper()
+	buildOptions := api.BuildOptions{
+		EntryPoints:   []string{filepath.Join(dir, filepath.FromSlash(entry))},
+		Bundle:        true,
+		Write:         false,
+		Outdir:        filepath.Join(dir, "dist"),
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: dir,
+		External:      []string{"vue"},
+	}
+	if configure != nil {
+		configure(&buildOptions)
+	}
+	options = append([]OptionFunc{WithJsExecutor(newCompilerExecutor(t))}, options...)
+	buildOptions.Plugins = append(buildOptions.Plugins, NewPlugin(options...))
+	return api.Build(buildOptions)
+}
+
+// outputFile returns the contents of the first output file with the extension, or an empty string
+func outputFile(result api.BuildResult, ext string) string {
+	for _, file := range result.OutputFiles {
+		if filepath.Ext(file.Path) == ext {
+			return string(file.Contents)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Build modes with predefined defaults, custom modes are built like ModeProduction with their own MODE.
+const (
+	ModeDevelopment = "development" // Development build with source maps and Vue warnings
+	ModeProduction  = "production"  // Minified production build
+)
+
+// VueFeatureFlags holds the compile time feature flags of Vue, defined as globals in the build.
+// See https://vuejs.org/api/compile-time-flags.html
+type VueFeatureFlags struct {
+	OptionsApi                   bool // __VUE_OPTIONS_API__, support of the Options API
+	ProdDevtools                 bool // __VUE_PROD_DEVTOOLS__, devtools support in production builds
+	ProdHydrationMismatchDetails bool // __VUE_PROD_HYDRATION_MISMATCH_DETAILS__, detailed hydration mismatch warnings in production builds
+}
+
+// DefaultVueFeatureFlags returns the feature flags used when neither WithVueFeatureFlags nor Define sets them.
+func DefaultVueFeatureFlags() VueFeatureFlags {
+	return VueFeatureFlags{
+		OptionsApi:                   true,
+		ProdDevtools:                 false,
+		ProdHydrationMismatchDetails: false,
+	}
+}
+
+// defines returns the flags by their global name.
+func (f VueFeatureFlags) defines() []struct {
+	name  string
+	value bool
+} {
+	return []struct {
+		name  string
+		value bool
+	}{
+		{"__VUE_OPTIONS_API__", f.OptionsApi},
+		{"__VUE_PROD_DEVTOOLS__", f.ProdDevtools},
+		{"__VUE_PROD_HYDRATION_MISMATCH_DETAILS__", f.ProdHydrationMismatchDetails},
+	}
+}
+
+// applyBuildMode defines the environment of the configured mode and the Vue feature flags.
+// Values already defined by the build must agree with the mode and the flags, otherwise an error
+// is returned. The remaining defaults are set by normalizeEsbuildOptions.
+func applyBuildMode(opts *Options, buildOptions *api.BuildOptions) error {
+	if buildOptions.Define == nil {
+		buildOptions.Define = make(map[string]string)
+	}
+
+	// Step 1: Define MODE, DEV, PROD and NODE_ENV consistently for the mode
+	if opts.mode != "" {
+		isDev := opts.mode == ModeDevelopment
+		nodeEnv := ModeProduction
+		if isDev {
+			nodeEnv = ModeDevelopment
+		}
+		mode, _ := json.Marshal(opts.mode)
+		nodeEnvValue, _ := json.Marshal(nodeEnv)
+
+		env := []struct {
+			key   string
+			value interface{}
+			raw   string
+		}{
+			{"MODE", opts.mode, string(mode)},
+			{"DEV", isDev, fmt.Sprintf("%t", isDev)},
+			{"PROD", !isDev, fmt.Sprintf("%t", !isDev)},
+		}
+		for _, item := range env {
+			if v, exists := parseImportMetaEnv(buildOptions.Define, item.key); exists {
+				if v != item.value {
+					return fmt.Errorf("import.meta.env.%s is defined as %v, which conflicts with mode %q", item.key, v, opts.mode)
+				}
+				continue
+			}
+			buildOptions.Define["import.meta.env."+item.key] = item.raw
+		}
+
+		if raw, exists := buildOptions.Define["process.env.NODE_ENV"]; exists {
+			var v interface{}
+			json.Unmarshal([]byte(raw), &v)
+			if v != nodeEnv {
+				return fmt.Errorf("process.env.NODE_ENV is defined as %s, which conflicts with mode %q", raw, opts.mode)
+			}
+		} else {
+			buildOptions.Define["process.env.NODE_ENV"] = string(nodeEnvValue)
+		}
+
+		// Development builds map errors back to the sources, other builds are minified unless configured otherwise
+		if isDev {
+			if opts.sourcemap == nil && buildOptions.Sourcemap == api.SourceMapNone {
+				buildOptions.Sourcemap = api.SourceMapInline
+				if buildOptions.Outdir != "" || buildOptions.Outfile != "" {
+					buildOptions.Sourcemap = api.SourceMapLinked
+				}
+			}
+		} else if opts.minify == nil && !buildOptions.MinifyWhitespace && !buildOptions.MinifyIdentifiers && !buildOptions.MinifySyntax {
+			buildOptions.MinifyWhitespace = true
+			buildOptions.MinifyIdentifiers = true
+			buildOptions.MinifySyntax = true
+		}
+	}
+
+	// Step 2: Apply the configured minification and source maps, which can also turn off the defaults
+	if opts.minify != nil {
+		buildOptions.MinifyWhitespace = *opts.minify
+		buildOptions.MinifyIdentifiers = *opts.minify
+		buildOptions.MinifySyntax = *opts.minify
+	}
+	if opts.sourcemap != nil {
+		buildOptions.Sourcemap = *opts.sourcemap
+	}
+
+	// Step 3: Define the Vue feature flags, flags defined by the build must be booleans
+	flags := DefaultVueFeatureFlags()
+	if opts.vueFeatureFlags != nil {
+		flags = *opts.vueFeatureFlags
+	}
+	for _, flag := range flags.defines() {
+		raw, exists := buildOptions.Define[flag.name]
+		if !exists {
+			buildOptions.Define[flag.name] = fmt.Sprintf("%t", flag.value)
+			continue
+		}
+		if raw != "true" && raw != "false" {
+			return fmt.Errorf("invalid value %q for Vue feature flag %s, expected true or false", raw, flag.name)
+		}
+		if opts.vueFeatureFlags != nil && raw != fmt.Sprintf("%t", flag.value) {
+			return fmt.Errorf("feature flag %s is defined as %s, which conflicts with the configured Vue feature flags", flag.name, raw)
+		}
+	}
+	return nil
+}
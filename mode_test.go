@@ -0,0 +1,229 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestApplyBuildMode(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		flags         *VueFeatureFlags
+		buildOptions  api.BuildOptions
+		expectDefines map[string]string
+		expectError   string
+	}{
+		{
+			name: "no_mode",
+			expectDefines: map[string]string{
+				"__VUE_OPTIONS_API__":                     "true",
+				"__VUE_PROD_DEVTOOLS__":                   "false",
+				"__VUE_PROD_HYDRATION_MISMATCH_DETAILS__": "false",
+			},
+		},
+		{
+			name: "development",
+			mode: ModeDevelopment,
+			expectDefines: map[string]string{
+				"import.meta.env.MODE": `"development"`,
+				"import.meta.env.DEV":  "true",
+				"import.meta.env.PROD": "false",
+				"process.env.NODE_ENV": `"development"`,
+			},
+		},
+		{
+			name: "production",
+			mode: ModeProduction,
+			expectDefines: map[string]string{
+				"import.meta.env.MODE": `"production"`,
+				"import.meta.env.DEV":  "false",
+				"import.meta.env.PROD": "true",
+				"process.env.NODE_ENV": `"production"`,
+			},
+		},
+		{
+			name: "custom_mode",
+			mode: "staging",
+			expectDefines: map[string]string{
+				"import.meta.env.MODE": `"staging"`,
+				"import.meta.env.PROD": "true",
+				"process.env.NODE_ENV": `"production"`,
+			},
+		},
+		{
+			name:         "consistent_defines",
+			mode:         ModeDevelopment,
+			buildOptions: api.BuildOptions{Define: map[string]string{"import.meta.env": `{"DEV": true}`, "process.env.NODE_ENV": `"development"`}},
+			expectDefines: map[string]string{
+				"import.meta.env.PROD": "false",
+				"process.env.NODE_ENV": `"development"`,
+			},
+		},
+		{
+			name:         "conflicting_env",
+			mode:         ModeDevelopment,
+			buildOptions: api.BuildOptions{Define: map[string]string{"import.meta.env.PROD": "true"}},
+			expectError:  `import.meta.env.PROD is defined as true, which conflicts with mode "development"`,
+		},
+		{
+			name:         "conflicting_node_env",
+			mode:         ModeProduction,
+			buildOptions: api.BuildOptions{Define: map[string]string{"process.env.NODE_ENV": `"development"`}},
+			expectError:  "process.env.NODE_ENV is defined as",
+		},
+		{
+			name:  "feature_flags",
+			flags: &VueFeatureFlags{OptionsApi: false, ProdDevtools: true},
+			expectDefines: map[string]string{
+				"__VUE_OPTIONS_API__":   "false",
+				"__VUE_PROD_DEVTOOLS__": "true",
+			},
+		},
+		{
+			name:          "defined_feature_flag",
+			buildOptions:  api.BuildOptions{Define: map[string]string{"__VUE_OPTIONS_API__": "false"}},
+			expectDefines: map[string]string{"__VUE_OPTIONS_API__": "false"},
+		},
+		{
+			name:         "invalid_feature_flag",
+			buildOptions: api.BuildOptions{Define: map[string]string{"__VUE_PROD_DEVTOOLS__": "'yes'"}},
+			expectError:  "invalid value \"'yes'\" for Vue feature flag __VUE_PROD_DEVTOOLS__",
+		},
+		{
+			name:         "conflicting_feature_flag",
+			flags:        &VueFeatureFlags{OptionsApi: true},
+			buildOptions: api.BuildOptions{Define: map[string]string{"__VUE_OPTIONS_API__": "false"}},
+			expectError:  "feature flag __VUE_OPTIONS_API__ is defined as false",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := newOptions()
+			opts.mode = test.mode
+			opts.vueFeatureFlags = test.flags
+			err := applyBuildMode(opts, &test.buildOptions)
+			if test.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("Expected error containing '%s', got: %v", test.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			for key, expected := range test.expectDefines {
+				if test.buildOptions.Define[key] != expected {
+					t.Errorf("Expected %s to be %s, got %s", key, expected, test.buildOptions.Define[key])
+				}
+			}
+		})
+	}
+}
+
+func TestApplyBuildModeDefaults(t *testing.T) {
+	tests := []struct {
+		name            string
+		mode            string
+		options         []OptionFunc
+		buildOptions    api.BuildOptions
+		expectSourcemap api.SourceMap
+		expectMinify    bool
+	}{
+		{"no_mode", "", nil, api.BuildOptions{}, api.SourceMapNone, false},
+		{"development", ModeDevelopment, nil, api.BuildOptions{}, api.SourceMapInline, false},
+		{"development_outdir", ModeDevelopment, nil, api.BuildOptions{Outdir: "dist"}, api.SourceMapLinked, false},
+		{"development_sourcemap", ModeDevelopment, nil, api.BuildOptions{Sourcemap: api.SourceMapExternal}, api.SourceMapExternal, false},
+		{"development_without_sourcemap", ModeDevelopment, []OptionFunc{WithSourcemap(api.SourceMapNone)}, api.BuildOptions{}, api.SourceMapNone, false},
+		{"production", ModeProduction, nil, api.BuildOptions{}, api.SourceMapNone, true},
+		{"production_minify_configured", ModeProduction, nil, api.BuildOptions{MinifySyntax: true}, api.SourceMapNone, false},
+		{"production_without_minify", ModeProduction, []OptionFunc{WithMinify(false)}, api.BuildOptions{}, api.SourceMapNone, false},
+		{"custom_mode", "staging", nil, api.BuildOptions{}, api.SourceMapNone, true},
+		{"minify_without_mode", "", []OptionFunc{WithMinify(true), WithSourcemap(api.SourceMapExternal)}, api.BuildOptions{}, api.SourceMapExternal, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := newOptions()
+			opts.mode = test.mode
+			for _, option := range test.options {
+				option(opts)
+			}
+			if err := applyBuildMode(opts, &test.buildOptions); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if test.buildOptions.Sourcemap != test.expectSourcemap {
+				t.Errorf("Expected sourcemap %v, got %v", test.expectSourcemap, test.buildOptions.Sourcemap)
+			}
+			minified := test.buildOptions.MinifyWhitespace && test.buildOptions.MinifyIdentifiers
+			if minified != test.expectMinify {
+				t.Errorf("Expected minify %t, got %t", test.expectMinify, minified)
+			}
+		})
+	}
+}
+
+// Integration tests
+
+func TestVueBuildMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "App.vue"), []byte(`<template><div>Test</div></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log(App, import.meta.env.MODE, process.env.NODE_ENV);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	var isProd interface{}
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				isProd = req.Args[3].(map[string]interface{})["isProd"]
+			}
+		},
+	})
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelError,
+		AbsWorkingDir: tmpDir,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithMode(ModeDevelopment))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if isProd != false {
+		t.Errorf("Expected components to be compiled for development, got isProd %v", isProd)
+	}
+	output := string(result.OutputFiles[0].Contents)
+	if !strings.Contains(output, `"development", "development"`) || !strings.Contains(output, "sourceMappingURL=data:") {
+		t.Errorf("Expected development defines and inline source map, got:\n%s", output)
+	}
+
+	// Conflicting defines fail the build
+	result = api.Build(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		Define:        map[string]string{"__VUE_OPTIONS_API__": "yes"},
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec))},
+	})
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "invalid value") {
+		t.Errorf("Expected invalid feature flag error, got: %v", result.Errors)
+	}
+}
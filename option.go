@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	jsexecutor "github.com/buke/js-executor"
 	"github.com/evanw/esbuild/pkg/api"
@@ -51,6 +52,29 @@ type OnEndProcessor func(result *api.BuildResult, buildOptions *api.BuildOptions
 // Note: Dispose processors should not return errors as cleanup should be best-effort.
 type OnDisposeProcessor func(buildOptions *api.BuildOptions)
 
+// OnStyleProcessor is a function type for post-processing compiled CSS.
+// Receives the CSS code, metadata about its origin and BuildOptions, returns the (possibly transformed) CSS and error.
+// It runs on every SFC <style> block and every standalone stylesheet (Sass, Less, Stylus) output,
+// enabling vendor prefixing, design-token substitution or linting in one place.
+type OnStyleProcessor func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error)
+
+// TemplatePreprocessor is a function type for converting a <template> written in another language (e.g. Pug) to HTML.
+// Receives the template source, the path of the .vue file and BuildOptions, returns the HTML and error.
+// Return a *TemplatePreprocessError to report the position of an error within the template source.
+type TemplatePreprocessor func(source string, filePath string, buildOptions *api.BuildOptions) (string, error)
+
+// ScopeIdGenerator is a function type for generating the scope ID of a Vue component.
+// Receives the path and source of the .vue file and BuildOptions, returns the ID used in
+// data-v-* attributes and scoped selectors. See ContentHashScopeId and PathScopeId.
+type ScopeIdGenerator func(filePath, source string, buildOptions *api.BuildOptions) (string, error)
+
+// CustomBlockProcessor is a function type for processing custom blocks of Vue SFCs (e.g. <i18n>, <docs>).
+// Receives the custom block and BuildOptions, returns JavaScript code and error.
+// The returned code is imported by the component entry module. Following the vue-loader
+// convention, a default exported function is called with the component options:
+// export default function (Comp) { ... }
+type CustomBlockProcessor func(block CustomBlock, buildOptions *api.BuildOptions) (string, error)
+
 // IndexHtmlProcessor is a function type for processing HTML files after build.
 // Receives the HTML document node, BuildResult, plugin options, and PluginBuild context.
 // Can modify the HTML DOM, inject scripts/styles, or perform other HTML transformations.
@@ -70,19 +94,42 @@ type IndexHtmlOptions struct {
 // This is the internal configuration structure used by the plugin to manage
 // all settings, compiler options, and processor chains.
 type Options struct {
-	name                     string           // Plugin name for identification
-	templateCompilerOptions  map[string]any   // Vue template compiler configuration
-	stylePreprocessorOptions map[string]any   // Style preprocessor configuration (Sass, Less, etc.)
-	indexHtmlOptions         IndexHtmlOptions // HTML processing configuration
+	name                     string                   // Plugin name for identification
+	templateCompilerOptions  map[string]any           // Vue template compiler configuration
+	templateCompiler         *TemplateCompilerOptions // Typed Vue template compiler configuration, nil if not set
+	templateCompilerErr      error                    // Invalid template compiler configuration, reported when the build starts
+	stylePreprocessorOptions map[string]any           // Style preprocessor configuration (Sass, Less, etc.)
+	cssModulesOptions        map[string]any           // CSS Modules configuration for <style module> blocks
+	indexHtmlOptions         IndexHtmlOptions         // HTML processing configuration
+	prerenderOptions         *PrerenderOptions        // Static page rendering configuration, nil if disabled
+	assetUrls                *AssetUrlOptions         // Template asset URL transformation, nil uses the defaults
+	inlineTemplate           *bool                    // Inline templates into <script setup>, nil inlines them in production
 
 	// Processor chains for plugin extension points
 	onStartProcessors      []OnStartProcessor      // Executed before build starts
 	onVueResolveProcessors []OnVueResolveProcessor // Custom Vue file resolution logic
 	onVueLoadProcessors    []OnVueLoadProcessor    // Custom Vue file loading/preprocessing
 	onSassLoadProcessors   []OnSassLoadProcessor   // Custom Sass file loading/preprocessing
+	onStyleProcessors      []OnStyleProcessor      // Post-processing of compiled CSS
 	onEndProcessors        []OnEndProcessor        // Executed after build completes
 	onDisposeProcessors    []OnDisposeProcessor    // Executed during cleanup
 
+	customBlockProcessors map[string]CustomBlockProcessor // Custom block processors by tag name
+	templatePreprocessors map[string]TemplatePreprocessor // Template preprocessors by template language
+	scopeIdGenerator      ScopeIdGenerator                // Generates the scope ID of components
+	customElementPattern  *regexp.Regexp                  // Paths of components compiled in custom element mode, nil to disable
+	scopeIds              *scopeIdRegistry                // Scope IDs assigned in the current build
+	pathAlias             *pathAliasCache                 // Tsconfig path aliases of the current build
+	hmr                   *HmrServer                      // Server pushing component updates, nil if HMR is disabled
+	hmrTracker            *hmrTracker                     // Components of consecutive builds, compared for HMR
+	hmrUpdates            map[string]string               // Kinds of update by .vue path for HMR update builds, nil otherwise
+	compileCacheOptions   *CompileCacheOptions            // Compile cache configuration, nil if disabled
+	compileCache          *compileCache                   // Compile cache created by NewPlugin, nil if results are not cached
+	mode                  string                          // Build mode defining MODE, DEV, PROD and NODE_ENV, empty to keep the defines of the build
+	minify                *bool                           // Minify the output, nil for the default of the mode
+	sourcemap             *api.SourceMap                  // Source maps of the build, nil for the default of the mode
+	vueFeatureFlags       *VueFeatureFlags                // Vue compile time feature flags, nil to use the defaults
+
 	jsExecutor *jsexecutor.JsExecutor // JavaScript executor for Vue compilation
 	logger     *slog.Logger           // Logger for plugin messages
 }
@@ -95,10 +142,16 @@ type OptionFunc func(*Options)
 // Initializes empty maps for compiler options and sets up default logger.
 func newOptions() *Options {
 	return &Options{
-		name:                     "vue-plugin",         // Default plugin name
-		templateCompilerOptions:  make(map[string]any), // Empty template options
-		stylePreprocessorOptions: make(map[string]any), // Empty style options
-		logger:                   slog.Default(),       // Use default structured logger
+		name:                     "vue-plugin",                          // Default plugin name
+		templateCompilerOptions:  make(map[string]any),                  // Empty template options
+		stylePreprocessorOptions: make(map[string]any),                  // Empty style options
+		cssModulesOptions:        make(map[string]any),                  // Empty CSS Modules options
+		customBlockProcessors:    make(map[string]CustomBlockProcessor), // No custom block processors
+		templatePreprocessors:    make(map[string]TemplatePreprocessor), // Only plain HTML templates
+		scopeIdGenerator:         nil,                                   // Scope IDs derived from the source, or the path with HMR
+		scopeIds:                 newScopeIdRegistry(),                  // No scope IDs assigned yet
+		pathAlias:                &pathAliasCache{},                     // Parsed when the build starts
+		logger:                   slog.Default(),                        // Use default structured logger
 	}
 }
 
@@ -111,13 +164,40 @@ func WithName(name string) OptionFunc {
 }
 
 // WithTemplateCompilerOptions sets the Vue template compiler options.
-// These options are passed directly to the Vue compiler and can include:
-// - sourceMap: boolean - Generate source maps for templates
-// - compilerOptions: object - Vue compiler specific options
-// - transformAssetUrls: object - Asset URL transformation rules
-func WithTemplateCompilerOptions(templateCompilerOptions map[string]any) OptionFunc {
+// The options are either a map passed directly to the Vue compiler, or a TemplateCompilerOptions,
+// which also supports options that can't be serialized to JSON like an isCustomElement predicate.
+// The last call wins. Invalid options fail the build when it starts.
+func WithTemplateCompilerOptions(templateCompilerOptions any) OptionFunc {
+	return func(opts *Options) {
+		opts.templateCompiler = nil
+		opts.templateCompilerErr = nil
+		switch options := templateCompilerOptions.(type) {
+		case map[string]any:
+			opts.templateCompilerOptions = options
+		case TemplateCompilerOptions:
+			opts.templateCompilerOptions = make(map[string]any)
+			opts.templateCompiler = &options
+			opts.templateCompilerErr = options.validate()
+		default:
+			opts.templateCompilerErr = fmt.Errorf("invalid template compiler options of type %T, expected map[string]any or TemplateCompilerOptions", templateCompilerOptions)
+		}
+	}
+}
+
+// WithAssetUrls configures the transformation of asset URLs in templates into module imports.
+// It is enabled by default for the attributes of DefaultAssetUrlTags, set Disabled to keep URLs as written.
+func WithAssetUrls(assetUrls AssetUrlOptions) OptionFunc {
 	return func(opts *Options) {
-		opts.templateCompilerOptions = templateCompilerOptions
+		opts.assetUrls = &assetUrls
+	}
+}
+
+// WithInlineTemplate sets whether the templates of <script setup> components are compiled into the setup function.
+// Inlined templates give smaller and faster output, they are the default when import.meta.env.PROD is true.
+// Templates are never inlined when HMR is enabled, hot updates replace the separate render function.
+func WithInlineTemplate(inlineTemplate bool) OptionFunc {
+	return func(opts *Options) {
+		opts.inlineTemplate = &inlineTemplate
 	}
 }
 
@@ -132,6 +212,17 @@ func WithStylePreprocessorOptions(stylePreprocessorOptions map[string]any) Optio
 	}
 }
 
+// WithCssModulesOptions sets the CSS Modules options used for <style module> blocks.
+// These options are passed to postcss-modules and can include:
+// - generateScopedName: string - Naming pattern for hashed class names, e.g. "[name]__[local]___[hash:base64:5]"
+// - localsConvention: string - Class name export style (camelCase, camelCaseOnly, dashes, dashesOnly)
+// - scopeBehaviour: string - Whether classes are local or global by default
+func WithCssModulesOptions(cssModulesOptions map[string]any) OptionFunc {
+	return func(opts *Options) {
+		opts.cssModulesOptions = cssModulesOptions
+	}
+}
+
 // WithIndexHtmlOptions sets the HTML processing options.
 // Configures how HTML files are processed, including source/output paths and custom processors.
 func WithIndexHtmlOptions(indexHtmlOptions IndexHtmlOptions) OptionFunc {
@@ -140,6 +231,14 @@ func WithIndexHtmlOptions(indexHtmlOptions IndexHtmlOptions) OptionFunc {
 	}
 }
 
+// WithPrerender enables rendering routes to static HTML pages after the index.html is processed.
+// Each route is rendered with the server bundle and written to <OutDir>/<route>/index.html.
+func WithPrerender(prerenderOptions PrerenderOptions) OptionFunc {
+	return func(opts *Options) {
+		opts.prerenderOptions = &prerenderOptions
+	}
+}
+
 // WithOnStartProcessor adds an OnStartProcessor to the processor chain.
 // Start processors are executed before the build begins and can perform setup tasks,
 // validation, or environment preparation.
@@ -176,6 +275,15 @@ func WithOnSassLoadProcessor(processor OnSassLoadProcessor) OptionFunc {
 	}
 }
 
+// WithOnStyleProcessor adds an OnStyleProcessor to the processor chain.
+// Style processors receive the compiled CSS of every SFC style block and standalone stylesheet,
+// enabling vendor prefixing, token substitution or linting of all emitted CSS.
+func WithOnStyleProcessor(processor OnStyleProcessor) OptionFunc {
+	return func(opts *Options) {
+		opts.onStyleProcessors = append(opts.onStyleProcessors, processor)
+	}
+}
+
 // WithOnEndProcessor adds an OnEndProcessor to the processor chain.
 // End processors are executed after the build completes and can perform post-processing,
 // file copying, asset manipulation, or build result analysis.
@@ -194,6 +302,121 @@ func WithOnDisposeProcessor(processor OnDisposeProcessor) OptionFunc {
 	}
 }
 
+// WithCustomBlockProcessor registers a processor for custom blocks with the given tag name.
+// Custom blocks without a registered processor are dropped with a build warning.
+// Registering a processor for the same tag again replaces the previous one.
+func WithCustomBlockProcessor(tag string, processor CustomBlockProcessor) OptionFunc {
+	return func(opts *Options) {
+		opts.customBlockProcessors[tag] = processor
+	}
+}
+
+// WithTemplatePreprocessor registers a preprocessor for templates with the given lang attribute,
+// e.g. "pug" for <template lang="pug">. The preprocessor output replaces the template content
+// before it is compiled. Registering a preprocessor for the same language again replaces the previous one.
+func WithTemplatePreprocessor(lang string, preprocessor TemplatePreprocessor) OptionFunc {
+	return func(opts *Options) {
+		opts.templatePreprocessors[lang] = preprocessor
+	}
+}
+
+// WithScopeIdGenerator sets the strategy used to generate the scope IDs of components.
+// Defaults to ContentHashScopeId, or PathScopeId("") when HMR is enabled. Use PathScopeId for IDs that stay stable across edits
+// or provide a custom generator. Collisions between components are reported as build warnings.
+func WithScopeIdGenerator(generator ScopeIdGenerator) OptionFunc {
+	return func(opts *Options) {
+		opts.scopeIdGenerator = generator
+	}
+}
+
+// WithCustomElementPattern sets the pattern of the component paths compiled in custom element mode.
+// Styles of these components are inlined into the component's styles array, as expected by
+// defineCustomElement, instead of being emitted as global CSS. Custom element mode is disabled by default,
+// regexp.MustCompile(`\.ce\.vue$`) enables it for files ending in .ce.vue like the Vite plugin.
+func WithCustomElementPattern(pattern *regexp.Regexp) OptionFunc {
+	return func(opts *Options) {
+		opts.customElementPattern = pattern
+	}
+}
+
+// WithHmr enables Hot Module Replacement of Vue components for esbuild contexts.
+// After every rebuild, template changes rerender and script changes reload the affected
+// components in the browser through the given server, other changes reload the page.
+// Components register themselves with the Vue HMR runtime, which requires a development build of Vue.
+func WithHmr(server *HmrServer) OptionFunc {
+	return func(opts *Options) {
+		opts.hmr = server
+		opts.hmrTracker = newHmrTracker()
+	}
+}
+
+// hmrEnabled reports whether components are compiled with HMR support.
+func (opts *Options) hmrEnabled() bool {
+	return opts.hmr != nil || opts.hmrUpdates != nil
+}
+
+// WithMemoryCache keeps compiled SFCs and Sass stylesheets in memory between rebuilds of an esbuild context,
+// so a rebuild only compiles the files that were edited or whose loaded files changed.
+// The persistent compile cache also keeps its results in memory.
+func WithMemoryCache() OptionFunc {
+	return func(opts *Options) {
+		if opts.compileCacheOptions == nil {
+			opts.compileCacheOptions = &CompileCacheOptions{}
+		}
+	}
+}
+
+// WithCompileCache enables the persistent compile cache in the given directory with default limits.
+// Compiled SFCs and Sass stylesheets are stored on disk and reused by later builds, as long as
+// the source, the compiler options, the compiler bundle and the files loaded by the compilation are unchanged.
+func WithCompileCache(dir string) OptionFunc {
+	return WithCompileCacheOptions(CompileCacheOptions{Dir: dir})
+}
+
+// WithCompileCacheOptions enables the persistent compile cache with the given options.
+// Set CompilerHash when the JS executor runs a compiler bundle other than the one embedded in
+// this module, e.g. a modified bundle during development.
+func WithCompileCacheOptions(compileCacheOptions CompileCacheOptions) OptionFunc {
+	return func(opts *Options) {
+		opts.compileCacheOptions = &compileCacheOptions
+	}
+}
+
+// WithMode sets the build mode, e.g. ModeDevelopment or ModeProduction.
+// The mode defines import.meta.env.MODE, DEV, PROD and process.env.NODE_ENV consistently. Custom modes
+// like "staging" are built for production with their own MODE, as in Vite. Development builds emit
+// source maps and production builds are minified, unless the build options or WithSourcemap and
+// WithMinify configure them.
+func WithMode(mode string) OptionFunc {
+	return func(opts *Options) {
+		opts.mode = mode
+	}
+}
+
+// WithMinify sets whether the output is minified, overriding the default of the mode and the
+// Minify options of the build. WithMinify(false) keeps production builds unminified.
+func WithMinify(enabled bool) OptionFunc {
+	return func(opts *Options) {
+		opts.minify = &enabled
+	}
+}
+
+// WithSourcemap sets the source maps of the build, overriding the default of the mode and the
+// Sourcemap option of the build. WithSourcemap(api.SourceMapNone) disables them in development builds.
+func WithSourcemap(sourcemap api.SourceMap) OptionFunc {
+	return func(opts *Options) {
+		opts.sourcemap = &sourcemap
+	}
+}
+
+// WithVueFeatureFlags sets the compile time feature flags of Vue.
+// Defaults to DefaultVueFeatureFlags. Flags also defined in the build options must have the same value.
+func WithVueFeatureFlags(flags VueFeatureFlags) OptionFunc {
+	return func(opts *Options) {
+		opts.vueFeatureFlags = &flags
+	}
+}
+
 // WithJsExecutor sets the JavaScript executor for Vue compilation.
 // The JS executor is required and handles communication with the Vue compiler running in a JavaScript context.
 // It's used for compiling Vue Single File Components and processing style files.
@@ -269,16 +492,7 @@ func normalizeEsbuildOptions(initialOptions *api.BuildOptions) {
 		initialOptions.Define["import.meta.env.BASE_URL"] = "'/'"
 	}
 
-	// Configure Vue-specific feature flags for optimal bundle size and behavior
-	if _, ok := initialOptions.Define["__VUE_OPTIONS_API__"]; !ok {
-		initialOptions.Define["__VUE_OPTIONS_API__"] = fmt.Sprintf(`%t`, true)
-	}
-	if _, ok := initialOptions.Define["__VUE_PROD_DEVTOOLS__"]; !ok {
-		initialOptions.Define["__VUE_PROD_DEVTOOLS__"] = fmt.Sprintf(`%t`, false)
-	}
-	if _, ok := initialOptions.Define["__VUE_PROD_HYDRATION_MISMATCH_DETAILS__"]; !ok {
-		initialOptions.Define["__VUE_PROD_HYDRATION_MISMATCH_DETAILS__"] = fmt.Sprintf(`%t`, false)
-	}
+	// Vue feature flags are defined by applyBuildMode
 
 	// Enable metafile generation for build analysis and HTML processing
 	initialOptions.Metafile = true
@@ -6,6 +6,7 @@ package vueplugin
 import (
 	"log/slog"
 	"os"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
@@ -34,6 +35,58 @@ func TestWithTemplateCompilerOptions(t *testing.T) {
 	}
 }
 
+// TestWithTemplateCompilerOptionsTyped verifies that WithTemplateCompilerOptions sets and validates the typed template options.
+func TestWithTemplateCompilerOptionsTyped(t *testing.T) {
+	opts := newOptions()
+	if opts.templateCompiler != nil {
+		t.Error("Expected no typed template options by default")
+	}
+	WithTemplateCompilerOptions(TemplateCompilerOptions{Whitespace: "preserve"})(opts)
+	if opts.templateCompiler == nil || opts.templateCompiler.Whitespace != "preserve" || opts.templateCompilerErr != nil {
+		t.Errorf("Unexpected typed template options: %+v, %v", opts.templateCompiler, opts.templateCompilerErr)
+	}
+
+	WithTemplateCompilerOptions(TemplateCompilerOptions{CustomElements: []string{"/[/"}})(opts)
+	if opts.templateCompilerErr == nil {
+		t.Error("Expected error for an invalid custom element pattern")
+	}
+
+	// The last call wins
+	WithTemplateCompilerOptions(map[string]any{"whitespace": "preserve"})(opts)
+	if opts.templateCompiler != nil || opts.templateCompilerErr != nil || opts.templateCompilerOptions["whitespace"] != "preserve" {
+		t.Errorf("Expected the map options to replace the typed options, got %+v, %v", opts.templateCompiler, opts.templateCompilerErr)
+	}
+
+	WithTemplateCompilerOptions("whitespace")(opts)
+	if opts.templateCompilerErr == nil || !strings.Contains(opts.templateCompilerErr.Error(), "of type string") {
+		t.Errorf("Expected error for options of an unsupported type, got: %v", opts.templateCompilerErr)
+	}
+}
+
+// TestWithAssetUrls verifies that WithAssetUrls sets the asset URL options.
+func TestWithAssetUrls(t *testing.T) {
+	opts := newOptions()
+	if opts.assetUrls != nil {
+		t.Error("Expected default asset URL options")
+	}
+	WithAssetUrls(AssetUrlOptions{IncludeAbsolute: true})(opts)
+	if opts.assetUrls == nil || !opts.assetUrls.IncludeAbsolute {
+		t.Errorf("Unexpected asset URL options: %+v", opts.assetUrls)
+	}
+}
+
+// TestWithInlineTemplate verifies that WithInlineTemplate overrides the production default.
+func TestWithInlineTemplate(t *testing.T) {
+	opts := newOptions()
+	if opts.inlineTemplate != nil {
+		t.Error("Expected templates to be inlined by build mode by default")
+	}
+	WithInlineTemplate(false)(opts)
+	if opts.inlineTemplate == nil || *opts.inlineTemplate {
+		t.Errorf("Expected inlined templates to be disabled, got %v", opts.inlineTemplate)
+	}
+}
+
 // TestWithStylePreprocessorOptions verifies that WithStylePreprocessorOptions sets style options.
 func TestWithStylePreprocessorOptions(t *testing.T) {
 	opts := newOptions()
@@ -44,6 +97,119 @@ func TestWithStylePreprocessorOptions(t *testing.T) {
 	}
 }
 
+// TestWithCssModulesOptions verifies that WithCssModulesOptions sets the CSS Modules options.
+func TestWithCssModulesOptions(t *testing.T) {
+	opts := newOptions()
+	WithCssModulesOptions(map[string]any{"generateScopedName": "[local]_[hash:base64:5]"})(opts)
+	if opts.cssModulesOptions["generateScopedName"] != "[local]_[hash:base64:5]" {
+		t.Errorf("Expected cssModulesOptions to contain generateScopedName option")
+	}
+}
+
+// TestWithCustomBlockProcessor verifies that WithCustomBlockProcessor registers a processor by tag.
+func TestWithCustomBlockProcessor(t *testing.T) {
+	opts := newOptions()
+	processor := func(block CustomBlock, buildOptions *api.BuildOptions) (string, error) { return "", nil }
+	WithCustomBlockProcessor("i18n", processor)(opts)
+	if _, ok := opts.customBlockProcessors["i18n"]; !ok {
+		t.Errorf("Expected custom block processor for i18n to be registered")
+	}
+}
+
+// TestWithTemplatePreprocessor verifies that WithTemplatePreprocessor registers a preprocessor by language.
+func TestWithTemplatePreprocessor(t *testing.T) {
+	opts := newOptions()
+	preprocessor := func(source string, filePath string, buildOptions *api.BuildOptions) (string, error) {
+		return source, nil
+	}
+	WithTemplatePreprocessor("pug", preprocessor)(opts)
+	if _, ok := opts.templatePreprocessors["pug"]; !ok {
+		t.Errorf("Expected template preprocessor for pug to be registered")
+	}
+}
+
+// TestWithScopeIdGenerator verifies that WithScopeIdGenerator replaces the default generator.
+func TestWithScopeIdGenerator(t *testing.T) {
+	opts := newOptions()
+	WithScopeIdGenerator(func(filePath, source string, buildOptions *api.BuildOptions) (string, error) {
+		return "custom", nil
+	})(opts)
+	if id, _ := opts.scopeIdGenerator("/src/App.vue", "", &api.BuildOptions{}); id != "custom" {
+		t.Errorf("Expected custom scope ID generator, got %s", id)
+	}
+}
+
+func TestWithCompileCache(t *testing.T) {
+	opts := newOptions()
+	WithCompileCache("/tmp/cache")(opts)
+	if opts.compileCacheOptions == nil || opts.compileCacheOptions.Dir != "/tmp/cache" {
+		t.Errorf("Expected compile cache in /tmp/cache, got %+v", opts.compileCacheOptions)
+	}
+
+	WithCompileCacheOptions(CompileCacheOptions{Dir: "/tmp/other", CompilerHash: "abc", MaxSize: 1024})(opts)
+	if opts.compileCacheOptions.CompilerHash != "abc" || opts.compileCacheOptions.MaxSize != 1024 {
+		t.Errorf("Expected compile cache options to be set, got %+v", opts.compileCacheOptions)
+	}
+
+	// The persistent cache keeps its directory when the memory cache is enabled as well
+	WithMemoryCache()(opts)
+	if opts.compileCacheOptions.Dir != "/tmp/other" {
+		t.Errorf("Expected the persistent cache to be kept, got %+v", opts.compileCacheOptions)
+	}
+}
+
+func TestWithMemoryCache(t *testing.T) {
+	opts := newOptions()
+	if opts.compileCacheOptions != nil {
+		t.Errorf("Expected no compile cache by default, got %+v", opts.compileCacheOptions)
+	}
+	WithMemoryCache()(opts)
+	if opts.compileCacheOptions == nil || opts.compileCacheOptions.Dir != "" {
+		t.Errorf("Expected a memory-only compile cache, got %+v", opts.compileCacheOptions)
+	}
+}
+
+func TestWithCustomElementPattern(t *testing.T) {
+	opts := newOptions()
+	if opts.customElementPattern != nil {
+		t.Error("Expected custom element mode to be disabled by default")
+	}
+
+	WithCustomElementPattern(regexp.MustCompile(`/elements/.*\.vue$`))(opts)
+	if !opts.customElementPattern.MatchString("/src/elements/Widget.vue") {
+		t.Error("Expected custom pattern to be used")
+	}
+
+	WithCustomElementPattern(nil)(opts)
+	if opts.customElementPattern != nil {
+		t.Error("Expected custom element mode to be disabled")
+	}
+}
+
+// TestWithMode verifies that WithMode sets the build mode.
+func TestWithMode(t *testing.T) {
+	opts := newOptions()
+	if opts.mode != "" {
+		t.Errorf("Expected no mode by default, got %q", opts.mode)
+	}
+	WithMode(ModeDevelopment)(opts)
+	if opts.mode != ModeDevelopment {
+		t.Errorf("Expected mode %q, got %q", ModeDevelopment, opts.mode)
+	}
+}
+
+// TestWithVueFeatureFlags verifies that WithVueFeatureFlags sets the feature flags.
+func TestWithVueFeatureFlags(t *testing.T) {
+	opts := newOptions()
+	if opts.vueFeatureFlags != nil {
+		t.Error("Expected default feature flags")
+	}
+	WithVueFeatureFlags(VueFeatureFlags{ProdDevtools: true})(opts)
+	if opts.vueFeatureFlags == nil || !opts.vueFeatureFlags.ProdDevtools || opts.vueFeatureFlags.OptionsApi {
+		t.Errorf("Unexpected feature flags: %+v", opts.vueFeatureFlags)
+	}
+}
+
 // TestWithOnStartProcessor verifies that WithOnStartProcessor adds a processor.
 func TestWithOnStartProcessor(t *testing.T) {
 	opts := newOptions()
@@ -94,6 +260,16 @@ func TestWithOnSassLoadProcessor(t *testing.T) {
 	}
 }
 
+// TestWithOnStyleProcessor verifies that WithOnStyleProcessor adds a processor.
+func TestWithOnStyleProcessor(t *testing.T) {
+	opts := newOptions()
+	processor := func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) { return css, nil }
+	WithOnStyleProcessor(processor)(opts)
+	if len(opts.onStyleProcessors) != 1 {
+		t.Errorf("Expected 1 style processor, got %d", len(opts.onStyleProcessors))
+	}
+}
+
 // TestWithOnEndProcessor verifies that WithOnEndProcessor adds a processor.
 func TestWithOnEndProcessor(t *testing.T) {
 	opts := newOptions()
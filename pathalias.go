@@ -7,7 +7,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strings"
 
 	"github.com/evanw/esbuild/pkg/api"
 )
@@ -20,13 +20,45 @@ import (
 // 2. When Tsconfig file path is provided (reads from file system)
 //
 // It extracts compilerOptions.paths from the tsconfig and converts them to
-// absolute paths based on the tsconfig directory location.
+// absolute paths based on compilerOptions.baseUrl if set, otherwise on the tsconfig directory, like TypeScript.
 //
 // Returns a map where keys are alias patterns (e.g., "@/*") and values are
 // absolute file system paths (e.g., "/project/src/*").
 func parseTsconfigPathAlias(buildOptions *api.BuildOptions) (map[string]string, error) {
-	var tsconfigAbsDir string
 	pathAlias := make(map[string]string)
+	tsconfig, tsconfigAbsDir, err := readTsconfig(buildOptions)
+	if err != nil {
+		return pathAlias, err
+	}
+
+	// Extract path aliases from compilerOptions.paths if present
+	if compilerOptions, ok := tsconfig["compilerOptions"].(map[string]interface{}); ok {
+		// Paths are relative to baseUrl, which is relative to the tsconfig directory
+		if baseUrl, ok := compilerOptions["baseUrl"].(string); ok && baseUrl != "" {
+			tsconfigAbsDir = filepath.Join(tsconfigAbsDir, baseUrl)
+		}
+		if paths, ok := compilerOptions["paths"].(map[string]interface{}); ok {
+			// Process each alias definition
+			for alias, pathMappings := range paths {
+				// TypeScript paths can have multiple mappings, we use the first one
+				if pathArray, ok := pathMappings.([]interface{}); ok && len(pathArray) > 0 {
+					if pathStr, ok := pathArray[0].(string); ok {
+						// Convert relative path to absolute path based on baseUrl or the tsconfig directory
+						pathAlias[alias] = filepath.Join(tsconfigAbsDir, pathStr)
+					}
+				}
+			}
+		}
+	}
+
+	return pathAlias, nil
+}
+
+// readTsconfig reads the tsconfig of the build from TsconfigRaw or the Tsconfig file.
+// It returns the parsed tsconfig and the absolute directory its relative paths are based on,
+// or a nil tsconfig if the build configures none.
+func readTsconfig(buildOptions *api.BuildOptions) (map[string]interface{}, string, error) {
+	var tsconfigAbsDir string
 	var tsconfig map[string]interface{}
 
 	// Branch 1: Parse from raw tsconfig JSON if provided
@@ -34,7 +66,7 @@ func parseTsconfigPathAlias(buildOptions *api.BuildOptions) (map[string]string,
 		// Unmarshal the inline JSON configuration
 		err := json.Unmarshal([]byte(buildOptions.TsconfigRaw), &tsconfig)
 		if err != nil {
-			return pathAlias, err
+			return nil, "", err
 		}
 
 		// Determine the base directory for resolving relative paths
@@ -51,37 +83,21 @@ func parseTsconfigPathAlias(buildOptions *api.BuildOptions) (map[string]string,
 		// Open and read the tsconfig.json file
 		file, err := os.Open(buildOptions.Tsconfig)
 		if err != nil {
-			return pathAlias, err
+			return nil, "", err
 		}
 		defer file.Close()
 
 		// Decode JSON content from the file
 		err = json.NewDecoder(file).Decode(&tsconfig)
 		if err != nil {
-			return pathAlias, err
+			return nil, "", err
 		}
 
 		// Use the tsconfig file's directory as the base for relative paths
 		tsconfigAbsDir, _ = filepath.Abs(filepath.Dir(buildOptions.Tsconfig))
 	}
 
-	// Extract path aliases from compilerOptions.paths if present
-	if compilerOptions, ok := tsconfig["compilerOptions"].(map[string]interface{}); ok {
-		if paths, ok := compilerOptions["paths"].(map[string]interface{}); ok {
-			// Process each alias definition
-			for alias, pathMappings := range paths {
-				// TypeScript paths can have multiple mappings, we use the first one
-				if pathArray, ok := pathMappings.([]interface{}); ok && len(pathArray) > 0 {
-					if pathStr, ok := pathArray[0].(string); ok {
-						// Convert relative path to absolute path based on tsconfig directory
-						pathAlias[alias] = filepath.Join(tsconfigAbsDir, pathStr)
-					}
-				}
-			}
-		}
-	}
-
-	return pathAlias, nil
+	return tsconfig, tsconfigAbsDir, nil
 }
 
 // applyPathAlias applies path alias mapping to the given import path.
@@ -95,36 +111,60 @@ func parseTsconfigPathAlias(buildOptions *api.BuildOptions) (map[string]string,
 //   - The '*' captures any remaining path segments
 //   - The captured content is substituted in the target path
 //
+// Like TypeScript, exact aliases take precedence and wildcard aliases are matched longest prefix first,
+// so "@/components/*" wins over "@/*".
+//
 // Examples:
 //   - "@/components/Button" with "@/*" -> "/src/*" becomes "/src/components/Button"
 //   - "@utils" with "@utils" -> "/src/utils" becomes "/src/utils"
 //
 // Returns the original path if no alias matches.
 func applyPathAlias(pathAlias map[string]string, path string) string {
-	// Try each alias pattern against the input path
-	for alias, realPath := range pathAlias {
-		// Start with exact pattern matching using regex escaping
-		aliasPattern := "^" + regexp.QuoteMeta(alias)
-
-		// Handle wildcard patterns - '*' captures remaining path segments
-		if len(alias) > 0 && alias[len(alias)-1] == '*' {
-			// Remove the escaped '*' and add capture group for wildcard matching
-			aliasPattern = aliasPattern[:len(aliasPattern)-2] + "(.*)"
-			// Replace '*' in target path with captured content
-			realPath = realPath[:len(realPath)-1] + "$1"
-		} else {
-			// For exact aliases, ensure complete path matching
-			aliasPattern = aliasPattern + "$"
-		}
+	// Exact aliases must match the entire path
+	if realPath, ok := pathAlias[path]; ok && !strings.HasSuffix(path, "*") {
+		return realPath
+	}
 
-		// Compile and test the pattern
-		re := regexp.MustCompile(aliasPattern)
-		if re.MatchString(path) {
-			// Apply the alias transformation
-			return re.ReplaceAllString(path, realPath)
+	// Find the wildcard alias with the longest matching prefix, the prefixes of wildcard aliases are unique
+	var prefix, realPath string
+	matched := false
+	for alias, aliasPath := range pathAlias {
+		aliasPrefix, ok := strings.CutSuffix(alias, "*")
+		if !ok || !strings.HasPrefix(path, aliasPrefix) || (matched && len(aliasPrefix) <= len(prefix)) {
+			continue
 		}
+		prefix, realPath, matched = aliasPrefix, aliasPath, true
+	}
+	if !matched {
+		// No alias matched, return original path unchanged
+		return path
 	}
 
-	// No alias matched, return original path unchanged
-	return path
+	// Replace '*' in target path with the remaining path segments
+	return strings.TrimSuffix(realPath, "*") + path[len(prefix):]
+}
+
+// pathAliasCache holds the tsconfig path aliases of a build, parsed once when the build starts and
+// shared by the resolve and load handlers. HMR update builds reuse the aliases of the main build.
+type pathAliasCache struct {
+	pathAlias map[string]string
+	err       error
+}
+
+// load parses the path aliases of the build, errors are returned by get and apply
+func (c *pathAliasCache) load(buildOptions *api.BuildOptions) {
+	c.pathAlias, c.err = parseTsconfigPathAlias(buildOptions)
+}
+
+// get returns the path aliases of the build
+func (c *pathAliasCache) get() (map[string]string, error) {
+	return c.pathAlias, c.err
+}
+
+// apply applies the path aliases of the build to the given import path
+func (c *pathAliasCache) apply(path string) (string, error) {
+	if c.err != nil {
+		return path, c.err
+	}
+	return applyPathAlias(c.pathAlias, path), nil
 }
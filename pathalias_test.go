@@ -34,6 +34,25 @@ func TestParseTsconfigPathAliasWithTsconfigRaw(t *testing.T) {
 	}
 }
 
+// TestParseTsconfigPathAliasWithBaseUrl tests that paths are relative to baseUrl if set.
+func TestParseTsconfigPathAliasWithBaseUrl(t *testing.T) {
+	buildOptions := &api.BuildOptions{
+		TsconfigRaw:   `{"compilerOptions":{"baseUrl":"./src","paths":{"@/*":["./*"],"@shared":["../shared/index.ts"]}}}`,
+		AbsWorkingDir: "/test/project",
+	}
+
+	pathAlias, err := parseTsconfigPathAlias(buildOptions)
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if expected := filepath.Join("/test/project", "src", "*"); pathAlias["@/*"] != expected {
+		t.Errorf("Expected alias '@/*' to be '%s', got '%s'", expected, pathAlias["@/*"])
+	}
+	if expected := filepath.Join("/test/project", "shared", "index.ts"); pathAlias["@shared"] != expected {
+		t.Errorf("Expected alias '@shared' to be '%s', got '%s'", expected, pathAlias["@shared"])
+	}
+}
+
 // TestParseTsconfigPathAliasWithInvalidRawJSON tests parsing with invalid raw JSON.
 func TestParseTsconfigPathAliasWithInvalidRawJSON(t *testing.T) {
 	buildOptions := &api.BuildOptions{
@@ -215,6 +234,61 @@ func TestApplyPathAliasEmptyMap(t *testing.T) {
 	}
 }
 
+// TestApplyPathAliasLongestPrefix tests that exact aliases win and wildcard aliases match longest prefix first.
+func TestApplyPathAliasLongestPrefix(t *testing.T) {
+	pathAlias := map[string]string{
+		"@/*":              "/src/*",
+		"@/components/*":   "/ui/components/*",
+		"@/components/ext": "/vendor/ext",
+		"*":                "/types/*",
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"@/components/Button.vue", "/ui/components/Button.vue"},
+		{"@/components/ext", "/vendor/ext"},
+		{"@/views/Home.vue", "/src/views/Home.vue"},
+		{"lodash", "/types/lodash"},
+	}
+
+	// Map iteration order is random, the result must not depend on it
+	for i := 0; i < 20; i++ {
+		for _, test := range tests {
+			result := applyPathAlias(pathAlias, test.input)
+			if result != test.expected {
+				t.Fatalf("applyPathAlias(%q) = %q, expected %q", test.input, result, test.expected)
+			}
+		}
+	}
+}
+
+// TestPathAliasCache tests that the aliases of a build are parsed once and parse errors are returned when applied.
+func TestPathAliasCache(t *testing.T) {
+	buildOptions := &api.BuildOptions{
+		TsconfigRaw:   `{"compilerOptions":{"paths":{"@/*":["./src/*"]}}}`,
+		AbsWorkingDir: "/test/project",
+	}
+	cache := &pathAliasCache{}
+	cache.load(buildOptions)
+
+	// Later changes of the options only apply to the next build
+	buildOptions.TsconfigRaw = `{invalid json}`
+	path, err := cache.apply("@/main.ts")
+	if err != nil || path != filepath.Join("/test/project", "src", "main.ts") {
+		t.Errorf("Expected aliased path, got %q, %v", path, err)
+	}
+
+	cache.load(buildOptions)
+	if _, err := cache.apply("@/main.ts"); err == nil {
+		t.Error("Expected error for an invalid tsconfig")
+	}
+	if _, err := cache.get(); err == nil {
+		t.Error("Expected error for an invalid tsconfig")
+	}
+}
+
 // TestParseTsconfigPathAliasWithComplexPaths tests parsing with complex path configurations.
 func TestParseTsconfigPathAliasWithComplexPaths(t *testing.T) {
 	buildOptions := &api.BuildOptions{
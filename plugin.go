@@ -15,7 +15,7 @@ import (
 // - Custom processor chains for various build phases
 //
 // The plugin handles Vue Single File Components (.vue), Sass files (.scss/.sass),
-// and HTML files with comprehensive build integration.
+// Less files (.less), Stylus files (.styl/.stylus) and HTML files with comprehensive build integration.
 //
 // Example usage:
 //
@@ -39,15 +39,50 @@ func NewPlugin(optsFunc ...OptionFunc) api.Plugin {
 		panic("jsExecutor is required, please set it using WithJsExecutor()")
 	}
 
+	// Create the compile cache once all options, including the logger, are applied
+	// Results are kept in memory between rebuilds, and on disk if a cache directory is set
+	if opts.compileCacheOptions != nil {
+		opts.compileCache = newCompileCache(*opts.compileCacheOptions, opts.logger)
+	}
+
+	return newPlugin(opts)
+}
+
+// newPlugin creates the esbuild plugin for the given, already validated options.
+// It is also used for the builds of HMR updates, which share the options of the main build.
+func newPlugin(opts *Options) api.Plugin {
 	return api.Plugin{
 		Name: opts.name, // Plugin name for identification in esbuild logs
 		Setup: func(build api.PluginBuild) {
 			// Step 1: Normalize and validate esbuild options for compatibility
+			// Setup can't fail, an invalid mode or feature flag fails the build when it starts
+			modeErr := applyBuildMode(opts, build.InitialOptions)
 			normalizeEsbuildOptions(build.InitialOptions)
+			applyAssetLoaders(opts, build.InitialOptions)
 
 			// Step 2: Register start processor chain - executed before build starts
 			// This allows for pre-build initialization, configuration validation, etc.
 			build.OnStart(func() (api.OnStartResult, error) {
+				if modeErr != nil {
+					opts.logger.Error("Invalid build mode", "error", modeErr)
+					return api.OnStartResult{}, modeErr
+				}
+				if opts.templateCompilerErr != nil {
+					opts.logger.Error("Invalid template compiler options", "error", opts.templateCompilerErr)
+					return api.OnStartResult{}, opts.templateCompilerErr
+				}
+
+				// Path aliases are parsed once per build, HMR update builds reuse the aliases of the main build
+				if opts.hmrUpdates == nil {
+					opts.pathAlias.load(build.InitialOptions)
+				}
+
+				// Drop cached results unused by the previous build, HMR update builds
+				// share the cache of the main build and must not drop its entries
+				if opts.compileCache != nil && opts.hmrUpdates == nil {
+					opts.compileCache.begin()
+				}
+
 				// Execute all registered start processors in sequence
 				for _, processor := range opts.onStartProcessors {
 					if err := processor(build.InitialOptions); err != nil {
@@ -59,9 +94,16 @@ func NewPlugin(optsFunc ...OptionFunc) api.Plugin {
 			})
 
 			// Step 3: Register all file type handlers for comprehensive support
-			setupVueHandler(opts, &build)  // Handle .vue Single File Components
-			setupSassHandler(opts, &build) // Handle .scss/.sass style files
-			setupHtmlHandler(opts, &build) // Handle .html template files
+			setupVueHandler(opts, &build)    // Handle .vue Single File Components
+			setupSassHandler(opts, &build)   // Handle .scss/.sass style files
+			setupLessHandler(opts, &build)   // Handle .less style files
+			setupStylusHandler(opts, &build) // Handle .styl/.stylus style files
+			setupHtmlHandler(opts, &build)   // Handle .html template files
+
+			// Push component updates to the browser after rebuilds
+			if opts.hmr != nil {
+				setupHmrHandler(opts, &build)
+			}
 
 			// Step 4: Register end processor chain - executed after all processing is done
 			// This allows for post-build processing, asset manipulation, cleanup, etc.
@@ -0,0 +1,217 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+	"golang.org/x/net/html"
+)
+
+// PrerenderOptions holds configuration options for rendering routes to static HTML pages.
+// Pages are rendered with a server bundle (see NewSsrRenderer) into the index.html processed by the
+// build, so IndexHtmlOptions must be configured as well.
+type PrerenderOptions struct {
+	ServerBundle   string                                                 // Path of the server bundle, built before the client build
+	EngineFactory  jsexecutor.JsEngineFactory                             // Creates the JS engines running the server bundle
+	Routes         []string                                               // Routes to render, e.g. "/" and "/about"
+	DiscoverRoutes func(buildOptions *api.BuildOptions) ([]string, error) // Returns more routes to render, e.g. from a CMS
+	OutDir         string                                                 // Directory pages are written to, defaults to the directory of IndexHtmlOptions.OutFile
+	AppId          string                                                 // ID of the element the app is mounted on, defaults to "app"
+	Context        func(route string) map[string]interface{}              // Returns the SSR context values of a route, optional
+}
+
+// prerenderRoutes renders every route with the server bundle into the processed index.html
+// and writes the pages to <OutDir>/<route>/index.html.
+// The page of "/" replaces the shell if both are written to the same file, so the home page is prerendered
+// with the default OutDir. The pages of other routes never overwrite the shell.
+func prerenderRoutes(opts *Options, shell []byte, buildOptions *api.BuildOptions) error {
+	prerenderOptions := opts.prerenderOptions
+
+	// Step 1: Collect the configured and discovered routes
+	routes := append([]string{}, prerenderOptions.Routes...)
+	if prerenderOptions.DiscoverRoutes != nil {
+		discovered, err := prerenderOptions.DiscoverRoutes(buildOptions)
+		if err != nil {
+			return fmt.Errorf("failed to discover prerender routes: %w", err)
+		}
+		routes = append(routes, discovered...)
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+
+	// Step 2: Load the server bundle, pages are rendered one after another by a single engine
+	if prerenderOptions.EngineFactory == nil {
+		return fmt.Errorf("prerender requires an engine factory to run the server bundle")
+	}
+	bundle, err := os.ReadFile(prerenderOptions.ServerBundle)
+	if err != nil {
+		return fmt.Errorf("failed to read server bundle: %w", err)
+	}
+	renderer, err := NewSsrRenderer(string(bundle), prerenderOptions.EngineFactory, jsexecutor.WithMinPoolSize(1))
+	if err != nil {
+		return err
+	}
+	defer renderer.Close()
+
+	outDir := prerenderOptions.OutDir
+	if outDir == "" {
+		outDir = filepath.Dir(opts.indexHtmlOptions.OutFile)
+	}
+	appId := prerenderOptions.AppId
+	if appId == "" {
+		appId = "app"
+	}
+	shellFile, _ := filepath.Abs(opts.indexHtmlOptions.OutFile)
+
+	// Step 3: Render every route into a copy of the shell
+	rendered := make(map[string]bool)
+	for _, route := range routes {
+		route, err := normalizeRoute(route)
+		if err != nil {
+			return err
+		}
+		if rendered[route] {
+			continue
+		}
+		rendered[route] = true
+
+		// Pages are rendered from the shell in memory, so only the home page may take its place
+		outFile := filepath.Join(outDir, filepath.FromSlash(strings.TrimPrefix(route, "/")), "index.html")
+		if absOutFile, _ := filepath.Abs(outFile); route != "/" && opts.indexHtmlOptions.OutFile != "" && absOutFile == shellFile {
+			return fmt.Errorf("page of route %s would overwrite %s, set PrerenderOptions.OutDir or another IndexHtmlOptions.OutFile", route, opts.indexHtmlOptions.OutFile)
+		}
+
+		var ctx map[string]interface{}
+		if prerenderOptions.Context != nil {
+			ctx = prerenderOptions.Context(route)
+		}
+		page, err := renderer.RenderToString(route, ctx)
+		if err != nil {
+			return err
+		}
+		contents, err := renderPrerenderedPage(shell, route, appId, page)
+		if err != nil {
+			return fmt.Errorf("failed to render page %s: %w", route, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(outFile), 0755); err != nil {
+			return fmt.Errorf("failed to create output dir for %s: %w", outFile, err)
+		}
+		if err := os.WriteFile(outFile, contents, 0644); err != nil {
+			return fmt.Errorf("failed to write page %s: %w", outFile, err)
+		}
+	}
+	return nil
+}
+
+// normalizeRoute cleans a route path, which must be absolute and can't carry a query or fragment.
+func normalizeRoute(route string) (string, error) {
+	if !strings.HasPrefix(route, "/") || strings.ContainsAny(route, "?#") {
+		return "", fmt.Errorf("invalid prerender route %q, routes must be absolute paths without query or fragment", route)
+	}
+	return path.Clean(route), nil
+}
+
+// renderPrerenderedPage injects a rendered page into a copy of the shell.
+// The app HTML replaces the content of the app container, teleports targeting "body" are appended
+// to the body and teleports targeting "#id" to the element with that ID.
+// The rendered HTML is inserted as is, reparsing it could change the markup hydration expects.
+// Relative URLs of the shell are rebased, since nested routes are written to nested directories.
+func renderPrerenderedPage(shell []byte, route, appId string, page *SsrRenderResult) ([]byte, error) {
+	doc, err := htmlquery.Parse(bytes.NewReader(shell))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse index.html: %w", err)
+	}
+
+	// Rendered fragments are marked by comments in the document, replaced once it is serialized
+	var fragments []string
+	insert := func(node *html.Node, fragment string) {
+		node.AppendChild(&html.Node{Type: html.CommentNode, Data: fmt.Sprintf("vue-prerender:%d", len(fragments))})
+		fragments = append(fragments, fragment)
+	}
+
+	app := htmlquery.FindOne(doc, fmt.Sprintf("//*[@id=%q]", appId))
+	if app == nil {
+		return nil, fmt.Errorf("app container #%s not found in index.html", appId)
+	}
+	for child := app.FirstChild; child != nil; child = app.FirstChild {
+		app.RemoveChild(child)
+	}
+	insert(app, page.Html)
+
+	// Teleports are inserted in a stable order
+	targets := make([]string, 0, len(page.Teleports))
+	for target := range page.Teleports {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		var node *html.Node
+		switch {
+		case target == "body":
+			node = htmlquery.FindOne(doc, "//body")
+		case strings.HasPrefix(target, "#"):
+			node = htmlquery.FindOne(doc, fmt.Sprintf("//*[@id=%q]", strings.TrimPrefix(target, "#")))
+		}
+		if node == nil {
+			continue
+		}
+		insert(node, page.Teleports[target])
+	}
+
+	if route != "/" {
+		rebaseRelativeUrls(doc, strings.Repeat("../", strings.Count(route, "/")))
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, err
+	}
+	contents := buf.String()
+	for i, fragment := range fragments {
+		contents = strings.Replace(contents, fmt.Sprintf("<!--vue-prerender:%d-->", i), fragment, 1)
+	}
+	return []byte(contents), nil
+}
+
+// rebaseRelativeUrls prefixes the relative URLs of scripts, stylesheets and images with prefix.
+func rebaseRelativeUrls(node *html.Node, prefix string) {
+	if node.Type == html.ElementNode {
+		attrKey := ""
+		switch node.Data {
+		case "script", "img":
+			attrKey = "src"
+		case "link":
+			attrKey = "href"
+		}
+		for i, attr := range node.Attr {
+			if attr.Key == attrKey && isRelativeUrl(attr.Val) {
+				node.Attr[i].Val = prefix + attr.Val
+			}
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		rebaseRelativeUrls(child, prefix)
+	}
+}
+
+// isRelativeUrl reports whether the URL is relative to the page, as opposed to absolute or root-relative URLs.
+func isRelativeUrl(url string) bool {
+	if url == "" || strings.HasPrefix(url, "/") || strings.HasPrefix(url, "#") {
+		return false
+	}
+	scheme, _, found := strings.Cut(url, ":")
+	return !found || strings.ContainsAny(scheme, "/?#.")
+}
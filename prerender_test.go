@@ -0,0 +1,292 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	quickjsengine "github.com/buke/js-executor/engines/quickjs-go"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// prerenderTestBundle is a server bundle stub rendering the route, exporting the same API as an
+// entry built with vue/server-renderer
+const prerenderTestBundle = `var ` + SsrEntryGlobalName + ` = (() => {
+	const createApp = (url, ssrContext) => ({ url, ssrContext });
+	const renderToString = async (app, ssrContext) => {
+		if (app.url === '/about') ssrContext.teleports = { '#modal': '<p>Modal</p>', body: '<div class="toast"></div>' };
+		return '<main>' + app.url + (ssrContext.title ? ' ' + ssrContext.title : '') + '</main>';
+	};
+	return { createApp, renderToString };
+})();`
+
+// Unit tests
+
+func TestNormalizeRoute(t *testing.T) {
+	tests := []struct {
+		route       string
+		expected    string
+		expectError bool
+	}{
+		{"/", "/", false},
+		{"/about/", "/about", false},
+		{"/blog/../docs/./intro", "/docs/intro", false},
+		{"/../../etc", "/etc", false},
+		{"about", "", true},
+		{"/search?q=vue", "", true},
+		{"/docs#intro", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.route, func(t *testing.T) {
+			route, err := normalizeRoute(test.route)
+			if test.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q", test.route)
+				}
+				return
+			}
+			if err != nil || route != test.expected {
+				t.Errorf("Expected %s, got %s, %v", test.expected, route, err)
+			}
+		})
+	}
+}
+
+func TestIsRelativeUrl(t *testing.T) {
+	tests := map[string]bool{
+		"main.js":                 true,
+		"./assets/main.css":       true,
+		"assets/a:b.js":           true,
+		"/main.js":                false,
+		"#top":                    false,
+		"":                        false,
+		"https://cdn.example.com": false,
+		"data:image/png;base64,":  false,
+	}
+	for url, expected := range tests {
+		if isRelativeUrl(url) != expected {
+			t.Errorf("Expected isRelativeUrl(%q) to be %t", url, expected)
+		}
+	}
+}
+
+func TestRenderPrerenderedPage(t *testing.T) {
+	shell := []byte(`<!DOCTYPE html><html><head><script type="module" src="main.js"></script>` +
+		`<link rel="stylesheet" href="/main.css"/></head><body><div id="app">Loading</div><div id="modal"></div></body></html>`)
+
+	page := &SsrRenderResult{
+		Html:      `<main>About</main>`,
+		Teleports: map[string]string{"#modal": "<p>Modal</p>", "body": `<div class="toast"></div>`, "#missing": "<p>Lost</p>"},
+	}
+	contents, err := renderPrerenderedPage(shell, "/blog/post", "app", page)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	output := string(contents)
+	for _, expected := range []string{
+		`<div id="app"><main>About</main></div>`,
+		`<div id="modal"><p>Modal</p></div>`,
+		`<div class="toast"></div></body>`,
+		`src="../../main.js"`,
+		`href="/main.css"`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain '%s', got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "Loading") || strings.Contains(output, "Lost") {
+		t.Errorf("Unexpected content in output:\n%s", output)
+	}
+
+	// The rendered HTML is inserted as is, e.g. without the tbody an HTML parser adds
+	hydrated := `<!--[--><table><tr><td>1</td></tr></table><p>a</p><!--]-->`
+	contents, err = renderPrerenderedPage(shell, "/", "app", &SsrRenderResult{Html: hydrated})
+	if err != nil || !strings.Contains(string(contents), `<div id="app">`+hydrated+`</div>`) {
+		t.Errorf("Expected the rendered HTML to be unchanged, got:\n%s, %v", contents, err)
+	}
+
+	// Pages at the root keep the URLs of the shell
+	contents, _ = renderPrerenderedPage(shell, "/", "app", &SsrRenderResult{Html: "<main>Home</main>"})
+	if !strings.Contains(string(contents), `src="main.js"`) {
+		t.Errorf("Expected unchanged URLs for the root page, got:\n%s", contents)
+	}
+
+	if _, err := renderPrerenderedPage(shell, "/", "root", page); err == nil || !strings.Contains(err.Error(), "app container #root not found") {
+		t.Errorf("Expected missing app container error, got: %v", err)
+	}
+}
+
+func TestPrerenderRoutes(t *testing.T) {
+	tmpDir := t.TempDir()
+	bundleFile := filepath.Join(tmpDir, "server.js")
+	if err := os.WriteFile(bundleFile, []byte(prerenderTestBundle), 0644); err != nil {
+		t.Fatalf("Failed to write server bundle: %v", err)
+	}
+	shell := []byte(`<html><head></head><body><div id="app"></div><div id="modal"></div></body></html>`)
+
+	opts := newOptions()
+	WithIndexHtmlOptions(IndexHtmlOptions{OutFile: filepath.Join(tmpDir, "dist", "index.html")})(opts)
+	WithPrerender(PrerenderOptions{
+		ServerBundle:  bundleFile,
+		EngineFactory: quickjsengine.NewFactory(),
+		Routes:        []string{"/", "/about", "/about/"},
+		DiscoverRoutes: func(buildOptions *api.BuildOptions) ([]string, error) {
+			return []string{"/blog/hello"}, nil
+		},
+		Context: func(route string) map[string]interface{} {
+			return map[string]interface{}{"title": "Title of " + route}
+		},
+	})(opts)
+
+	if err := prerenderRoutes(opts, shell, &api.BuildOptions{}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for file, expected := range map[string]string{
+		"dist/index.html":            "<main>/ Title of /</main>",
+		"dist/about/index.html":      "<main>/about Title of /about</main>",
+		"dist/blog/hello/index.html": "<main>/blog/hello Title of /blog/hello</main>",
+	} {
+		contents, err := os.ReadFile(filepath.Join(tmpDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", file, err)
+		}
+		if !strings.Contains(string(contents), expected) {
+			t.Errorf("Expected %s to contain '%s', got:\n%s", file, expected, contents)
+		}
+	}
+	about, _ := os.ReadFile(filepath.Join(tmpDir, "dist", "about", "index.html"))
+	if !strings.Contains(string(about), `<div id="modal"><p>Modal</p></div>`) || !strings.Contains(string(about), `<div class="toast"></div></body>`) {
+		t.Errorf("Expected teleports to be injected, got:\n%s", about)
+	}
+}
+
+func TestPrerenderRoutesErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	bundleFile := filepath.Join(tmpDir, "server.js")
+	if err := os.WriteFile(bundleFile, []byte(prerenderTestBundle), 0644); err != nil {
+		t.Fatalf("Failed to write server bundle: %v", err)
+	}
+	shell := []byte(`<html><body><div id="app"></div></body></html>`)
+
+	tests := []struct {
+		name             string
+		prerenderOptions PrerenderOptions
+		expectError      string
+	}{
+		{
+			name:             "no_routes",
+			prerenderOptions: PrerenderOptions{},
+		},
+		{
+			name: "discover_error",
+			prerenderOptions: PrerenderOptions{DiscoverRoutes: func(*api.BuildOptions) ([]string, error) {
+				return nil, fmt.Errorf("cms unavailable")
+			}},
+			expectError: "failed to discover prerender routes: cms unavailable",
+		},
+		{
+			name:             "missing_engine_factory",
+			prerenderOptions: PrerenderOptions{ServerBundle: bundleFile, Routes: []string{"/"}},
+			expectError:      "requires an engine factory",
+		},
+		{
+			name:             "missing_bundle",
+			prerenderOptions: PrerenderOptions{ServerBundle: filepath.Join(tmpDir, "missing.js"), EngineFactory: quickjsengine.NewFactory(), Routes: []string{"/"}},
+			expectError:      "failed to read server bundle",
+		},
+		{
+			name:             "invalid_route",
+			prerenderOptions: PrerenderOptions{ServerBundle: bundleFile, EngineFactory: quickjsengine.NewFactory(), Routes: []string{"about"}},
+			expectError:      "invalid prerender route",
+		},
+		{
+			name:             "overwrite_shell",
+			prerenderOptions: PrerenderOptions{ServerBundle: bundleFile, EngineFactory: quickjsengine.NewFactory(), Routes: []string{"/about"}},
+			expectError:      "would overwrite",
+		},
+		{
+			name:             "missing_app_container",
+			prerenderOptions: PrerenderOptions{ServerBundle: bundleFile, EngineFactory: quickjsengine.NewFactory(), Routes: []string{"/"}, AppId: "root"},
+			expectError:      "app container #root not found",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := newOptions()
+			test.prerenderOptions.OutDir = filepath.Join(tmpDir, test.name)
+			WithIndexHtmlOptions(IndexHtmlOptions{OutFile: filepath.Join(tmpDir, "overwrite_shell", "about", "index.html")})(opts)
+			WithPrerender(test.prerenderOptions)(opts)
+			err := prerenderRoutes(opts, shell, &api.BuildOptions{})
+			if test.expectError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectError) {
+				t.Errorf("Expected error containing '%s', got: %v", test.expectError, err)
+			}
+		})
+	}
+}
+
+// Integration tests
+
+func TestHtmlHandlerPrerender(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "App.vue"), []byte(`<template><div>Hello World</div></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "main.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	htmlSourceFile := filepath.Join(tmpDir, "index.html")
+	if err := os.WriteFile(htmlSourceFile, []byte(`<!DOCTYPE html><html><head></head><body><div id="app"></div></body></html>`), 0644); err != nil {
+		t.Fatalf("Failed to create HTML file: %v", err)
+	}
+	bundleFile := filepath.Join(tmpDir, "server.js")
+	if err := os.WriteFile(bundleFile, []byte(prerenderTestBundle), 0644); err != nil {
+		t.Fatalf("Failed to write server bundle: %v", err)
+	}
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+	})
+	outDir := filepath.Join(tmpDir, "dist")
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         true,
+		Outdir:        outDir,
+		LogLevel:      api.LogLevelError,
+		AbsWorkingDir: tmpDir,
+		Plugins: []api.Plugin{NewPlugin(
+			WithJsExecutor(jsExec),
+			WithIndexHtmlOptions(IndexHtmlOptions{SourceFile: htmlSourceFile, OutFile: filepath.Join(outDir, "index.html")}),
+			WithPrerender(PrerenderOptions{ServerBundle: bundleFile, EngineFactory: quickjsengine.NewFactory(), Routes: []string{"/", "/about"}}),
+		)},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	about, err := os.ReadFile(filepath.Join(outDir, "about", "index.html"))
+	if err != nil {
+		t.Fatalf("Expected the about page to be written: %v", err)
+	}
+	for _, expected := range []string{`<div id="app"><main>/about</main></div>`, `src="../main.js"`} {
+		if !strings.Contains(string(about), expected) {
+			t.Errorf("Expected the about page to contain '%s', got:\n%s", expected, about)
+		}
+	}
+	index, err := os.ReadFile(filepath.Join(outDir, "index.html"))
+	if err != nil || !strings.Contains(string(index), `<div id="app"><main>/</main></div>`) {
+		t.Errorf("Expected the index page to be rendered, got:\n%s, %v", index, err)
+	}
+}
@@ -28,20 +28,16 @@ func setupSassHandler(opts *Options, build *api.PluginBuild) {
 // Resolved Sass files are assigned to the "sass-loader" namespace for further processing.
 func registerSassResolveHandler(opts *Options, build *api.PluginBuild) {
 	build.OnResolve(api.OnResolveOptions{Filter: `\.s[ac]ss$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
-		// Parse TypeScript path aliases from tsconfig to support project-wide path mapping
-		pathAlias, err := parseTsconfigPathAlias(build.InitialOptions)
+		// Apply TypeScript path aliases to support imports like @/styles/main.scss
+		path, err := opts.pathAlias.apply(args.Path)
 		if err != nil {
 			opts.logger.Error("Failed to parse tsconfig path aliases", "error", err)
 			return api.OnResolveResult{}, err
 		}
 
-		// Apply path aliases to support imports like @/styles/main.scss
-		args.Path = applyPathAlias(pathAlias, args.Path)
-
 		// Convert relative paths to absolute paths for consistent file resolution
-		path := args.Path
-		if !filepath.IsAbs(args.Path) {
-			path = filepath.Clean(filepath.Join(args.ResolveDir, args.Path))
+		if !filepath.IsAbs(path) {
+			path = filepath.Clean(filepath.Join(args.ResolveDir, path))
 		}
 
 		return api.OnResolveResult{
@@ -71,7 +67,8 @@ func registerSassLoadHandler(opts *Options, build *api.PluginBuild) {
 		}
 
 		// Step 2: Compile Sass to CSS using the Vue compiler's integrated Sass service
-		css, err := compileSass(args.Path, source, opts.jsExecutor)
+		// Source maps are only generated when esbuild is asked to emit them
+		result, err := compileSass(args.Path, source, build.InitialOptions.Sourcemap > 0, opts.jsExecutor, opts.compileCache)
 		if err != nil {
 			opts.logger.Error("Failed to compile Sass", "error", err, "file", args.Path)
 			return api.OnLoadResult{
@@ -84,10 +81,27 @@ func registerSassLoadHandler(opts *Options, build *api.PluginBuild) {
 			}, err
 		}
 
-		// Step 3: Return compiled CSS with appropriate loader
+		// Step 3: Run the style processor chain and attach the source map so esbuild chains it back to the Sass sources
+		meta := StyleMeta{File: args.Path, Index: -1, Lang: stylesheetLang(args.Path)}
+		css, warnings, err := processStylesheet(opts, result.css, result.sourceMap, meta, build.InitialOptions)
+		if err != nil {
+			opts.logger.Error("Failed to process Sass output", "error", err, "file", args.Path)
+			return api.OnLoadResult{
+				Errors: []api.Message{{
+					Text: err.Error(),
+					Location: &api.Location{
+						File: args.Path,
+					},
+				}},
+			}, err
+		}
+
+		// Step 4: Return compiled CSS with appropriate loader, watching the loaded partials for rebuilds
 		return api.OnLoadResult{
-			Contents: &css,
-			Loader:   api.LoaderCSS, // Use CSS loader for the compiled output
+			Contents:   &css,
+			Loader:     api.LoaderCSS, // Use CSS loader for the compiled output
+			WatchFiles: result.imports,
+			Warnings:   warnings,
 		}, nil
 	})
 }
@@ -125,37 +139,58 @@ func readSassSource(args api.OnLoadArgs, opts *Options, build *api.PluginBuild)
 // It uses the integrated Sass compiler service that supports both .scss and .sass syntax.
 // The compilation includes dependency resolution and supports Sass features like imports,
 // variables, mixins, and functions.
-func compileSass(filePath, source string, jsExecutor *jsexecutor.JsExecutor) (string, error) {
+// If sourceMap is true, the source map is returned as a JSON string pointing at the
+// original Sass file and its imported partials, otherwise it is empty.
+// The files loaded by the compilation are returned as imports, so they can be watched.
+// With a compile cache, the result is reused while the source and the imported files are unchanged.
+func compileSass(filePath, source string, sourceMap bool, jsExecutor *jsexecutor.JsExecutor, cache *compileCache) (*stylesheetResult, error) {
 	// Extract directory path for Sass import resolution
 	location := filepath.Dir(filePath)
 
 	// Execute Sass compilation via the Vue compiler's Sass service
-	jsResponse, err := jsExecutor.Execute(&jsexecutor.JsRequest{
+	response, err := executeCached(cache, jsExecutor, &jsexecutor.JsRequest{
 		Id:      xid.New().String(),
 		Service: "sfc.sass.renderSync", // Vue compiler's integrated Sass service
 		Args: []interface{}{map[string]interface{}{
-			"data":         source,     // Sass source code to compile
-			"sasslocation": location,   // Base directory for resolving @import statements
-			"sourceMap":    false,      // Disable source maps for production builds
-			"style":        "expanded", // Output style: expanded, compressed, etc.
+			"data":         source,                // Sass source code to compile
+			"file":         toPosixPath(filePath), // Source name used in the source map
+			"sasslocation": location,              // Base directory for resolving @import statements
+			"sourceMap":    sourceMap,             // Generate source maps only when esbuild emits them
+			"style":        "expanded",            // Output style: expanded, compressed, etc.
 		}},
-	})
+	}, sassDependencies)
 
 	if err != nil {
-		return "", fmt.Errorf("sass compilation service failed: %w", err)
+		return nil, fmt.Errorf("sass compilation service failed: %w", err)
 	}
 
 	// Extract and validate compilation result
-	result, ok := jsResponse.Result.(map[string]interface{})
+	result, ok := response.(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("invalid response from sass compilation service")
+		return nil, fmt.Errorf("invalid response from sass compilation service")
 	}
 
 	// Extract the compiled CSS code from the result
 	code, ok := result["css"].(string)
 	if !ok {
-		return "", fmt.Errorf("failed to extract CSS from compilation result")
+		return nil, fmt.Errorf("failed to extract CSS from compilation result")
 	}
 
-	return code, nil
+	imports, _ := sassDependencies(result)
+	compiled := &stylesheetResult{css: code, imports: imports}
+
+	// The source map is optional, an empty string means no map was generated
+	if sourceMap {
+		compiled.sourceMap, _ = result["map"].(string)
+	}
+
+	return compiled, nil
+}
+
+// sassDependencies returns the files loaded by a Sass compilation, false if the result doesn't list them.
+func sassDependencies(result interface{}) ([]string, bool) {
+	compileResult, _ := result.(map[string]interface{})
+	stats, _ := compileResult["stats"].(map[string]interface{})
+	files, ok := stats["includedFiles"]
+	return toStringSlice(files), ok
 }
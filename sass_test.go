@@ -236,7 +236,7 @@ func TestCompileSass(t *testing.T) {
 			}
 			defer jsExec.Stop()
 
-			result, err := compileSass("/test/app.scss", "$primary: #333;", jsExec)
+			result, err := compileSass("/test/app.scss", "$primary: #333;", false, jsExec, nil)
 
 			if test.expectError {
 				if err == nil {
@@ -244,16 +244,33 @@ func TestCompileSass(t *testing.T) {
 				}
 			} else {
 				if err != nil {
-					t.Errorf("Expected no error, got: %v", err)
+					t.Fatalf("Expected no error, got: %v", err)
 				}
-				if result != test.expected {
-					t.Errorf("Expected CSS '%s', got '%s'", test.expected, result)
+				if result.css != test.expected {
+					t.Errorf("Expected CSS '%s', got '%s'", test.expected, result.css)
 				}
 			}
 		})
 	}
 }
 
+func TestCompileSassImports(t *testing.T) {
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Sass: &MockSassConfig{
+			CSS:   ".a { color: red; }",
+			Stats: &MockSassStatsConfig{IncludedFiles: []string{"/test/_variables.scss", "/test/_mixins.scss"}},
+		},
+	})
+
+	result, err := compileSass("/test/app.scss", "@use 'variables';", false, jsExec, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.imports) != 2 || result.imports[0] != "/test/_variables.scss" || result.imports[1] != "/test/_mixins.scss" {
+		t.Errorf("Expected loaded partials as imports, got %v", result.imports)
+	}
+}
+
 // Integration tests
 
 func TestSassCompilationWithMockEngine(t *testing.T) {
@@ -505,7 +522,7 @@ func TestSassCompilationServiceError(t *testing.T) {
 	}
 	defer jsExec.Stop()
 
-	_, err = compileSass("/test/error.scss", "$color: red;", jsExec)
+	_, err = compileSass("/test/error.scss", "$color: red;", false, jsExec, nil)
 	if err == nil {
 		t.Error("Expected error from JS executor service, got nil")
 	}
@@ -614,3 +631,33 @@ func TestSassLoadHandlerProcessorError(t *testing.T) {
 		t.Errorf("Expected error containing 'sass processor failed: custom error', got: %v", result.Errors)
 	}
 }
+
+// TestSassWatchFilesWithCompiler tests that the compiler bundle reports the partials loaded by Sass
+func TestSassWatchFilesWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"app.scss":        "@use 'variables';\n.app { color: variables.$primary; }",
+		"_variables.scss": "$primary: #42b883;",
+	})
+
+	filePath := filepath.Join(tmpDir, "app.scss")
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read app.scss: %v", err)
+	}
+	result, err := compileSass(filePath, string(source), false, newCompilerExecutor(t), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.Contains(result.css, "#42b883") {
+		t.Errorf("Expected the partial to be compiled, got:\n%s", result.css)
+	}
+	partial := filepath.Join(tmpDir, "_variables.scss")
+	found := false
+	for _, file := range result.imports {
+		found = found || filepath.Clean(filepath.FromSlash(file)) == partial
+	}
+	if !found {
+		t.Errorf("Expected %s to be watched, got %v", partial, result.imports)
+	}
+}
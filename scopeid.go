@@ -0,0 +1,93 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// ContentHashScopeId returns a ScopeIdGenerator deriving the scope ID from the component source.
+// This is the default strategy, every edit of a component changes its scoped selectors.
+func ContentHashScopeId() ScopeIdGenerator {
+	return func(filePath, source string, buildOptions *api.BuildOptions) (string, error) {
+		return generateHashId(source), nil
+	}
+}
+
+// PathScopeId returns a ScopeIdGenerator deriving the scope ID from the component path
+// relative to AbsWorkingDir (or the current directory) and the given salt.
+// The ID stays stable across edits, and with an empty salt it matches the IDs generated by
+// @vitejs/plugin-vue in development, keeping SSR and client builds of the same tree in sync.
+func PathScopeId(salt string) ScopeIdGenerator {
+	return func(filePath, source string, buildOptions *api.BuildOptions) (string, error) {
+		relPath, err := moduleId(filePath, buildOptions)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256([]byte(relPath + salt))
+		return hex.EncodeToString(sum[:])[:8], nil
+	}
+}
+
+// scopeIdRegex matches scope IDs usable in data-v-* attribute selectors
+var scopeIdRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// generateScopeId generates the scope ID of a component with the configured generator
+// and validates that it can be used in data-v-* attributes.
+// Without a configured generator, IDs are derived from the source, or from the path when HMR
+// is enabled, since components are found by their ID when they are updated.
+func generateScopeId(opts *Options, filePath, source string, buildOptions *api.BuildOptions) (string, error) {
+	generator := opts.scopeIdGenerator
+	if generator == nil {
+		generator = ContentHashScopeId()
+		if opts.hmrEnabled() {
+			generator = PathScopeId("")
+		}
+	}
+	id, err := generator(filePath, source, buildOptions)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate scope ID: %w", err)
+	}
+	if !scopeIdRegex.MatchString(id) {
+		return "", fmt.Errorf("invalid scope ID %q, only letters, digits, '-' and '_' are allowed", id)
+	}
+	return id, nil
+}
+
+// scopeIdRegistry records the scope IDs assigned during a build to detect collisions,
+// which would let scoped styles of one component leak into another.
+type scopeIdRegistry struct {
+	mu  sync.Mutex
+	ids map[string]string // Scope ID to the path of the component using it
+}
+
+// newScopeIdRegistry creates an empty scope ID registry.
+func newScopeIdRegistry() *scopeIdRegistry {
+	return &scopeIdRegistry{ids: make(map[string]string)}
+}
+
+// reset forgets all scope IDs, called at the start of every build.
+func (r *scopeIdRegistry) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = make(map[string]string)
+}
+
+// register assigns the scope ID to the component. If the ID is already used by another
+// component, the path of that component is returned together with false.
+func (r *scopeIdRegistry) register(id, filePath string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, exists := r.ids[id]; exists && existing != filePath {
+		return existing, false
+	}
+	r.ids[id] = filePath
+	return "", true
+}
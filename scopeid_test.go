@@ -0,0 +1,210 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestContentHashScopeId(t *testing.T) {
+	generator := ContentHashScopeId()
+	id, err := generator("/src/App.vue", "<template></template>", &api.BuildOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if id != generateHashId("<template></template>") {
+		t.Errorf("Expected content hash, got %s", id)
+	}
+}
+
+func TestPathScopeId(t *testing.T) {
+	buildOptions := &api.BuildOptions{AbsWorkingDir: filepath.FromSlash("/project")}
+	file := filepath.FromSlash("/project/src/App.vue")
+
+	tests := []struct {
+		name     string
+		salt     string
+		expectId string
+	}{
+		{"vite_compatible", "", "7a7a37b1"},
+		{"with_salt", "salt", "89e545d5"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := PathScopeId(test.salt)(file, "<template></template>", buildOptions)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if id != test.expectId {
+				t.Errorf("Expected %s, got %s", test.expectId, id)
+			}
+		})
+	}
+
+	// The ID must not depend on the component source
+	id1, _ := PathScopeId("")(file, "<template>a</template>", buildOptions)
+	id2, _ := PathScopeId("")(file, "<template>b</template>", buildOptions)
+	if id1 != id2 {
+		t.Errorf("Expected stable ID across edits, got %s and %s", id1, id2)
+	}
+
+	// Without AbsWorkingDir the path is relative to the current directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	id, err := PathScopeId("")(filepath.Join(wd, "src", "App.vue"), "", &api.BuildOptions{})
+	if err != nil || id != "7a7a37b1" {
+		t.Errorf("Expected ID relative to the working directory, got %s, %v", id, err)
+	}
+}
+
+func TestGenerateScopeId(t *testing.T) {
+	tests := []struct {
+		name        string
+		generator   ScopeIdGenerator
+		expectId    string
+		expectError string
+	}{
+		{"valid", func(string, string, *api.BuildOptions) (string, error) { return "app_1-a", nil }, "app_1-a", ""},
+		{"generator_error", func(string, string, *api.BuildOptions) (string, error) { return "", fmt.Errorf("boom") }, "", "failed to generate scope ID: boom"},
+		{"empty", func(string, string, *api.BuildOptions) (string, error) { return "", nil }, "", "invalid scope ID"},
+		{"invalid_characters", func(string, string, *api.BuildOptions) (string, error) { return "a b]", nil }, "", "invalid scope ID"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := newOptions()
+			WithScopeIdGenerator(test.generator)(opts)
+			id, err := generateScopeId(opts, "/src/App.vue", "", &api.BuildOptions{})
+			if test.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("Expected error containing '%s', got: %v", test.expectError, err)
+				}
+				return
+			}
+			if err != nil || id != test.expectId {
+				t.Errorf("Expected %s, got %s, %v", test.expectId, id, err)
+			}
+		})
+	}
+}
+
+func TestScopeIdRegistry(t *testing.T) {
+	registry := newScopeIdRegistry()
+	if _, ok := registry.register("abc", "/src/A.vue"); !ok {
+		t.Error("Expected first registration to succeed")
+	}
+	if _, ok := registry.register("abc", "/src/A.vue"); !ok {
+		t.Error("Expected registration of the same file to succeed")
+	}
+	if existing, ok := registry.register("abc", "/src/B.vue"); ok || existing != "/src/A.vue" {
+		t.Errorf("Expected collision with /src/A.vue, got %s, %v", existing, ok)
+	}
+
+	registry.reset()
+	if _, ok := registry.register("abc", "/src/B.vue"); !ok {
+		t.Error("Expected registration to succeed after reset")
+	}
+}
+
+// Integration tests
+
+func TestVueScopeIdStrategy(t *testing.T) {
+	tmpDir := t.TempDir()
+	vueFile := filepath.Join(tmpDir, "src", "App.vue")
+	if err := os.MkdirAll(filepath.Dir(vueFile), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(vueFile, []byte(`<template><div>Test</div></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './src/App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	var scopeId string
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				scopeId = req.Args[0].(string)
+			}
+		},
+	})
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelError,
+		AbsWorkingDir: tmpDir,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithScopeIdGenerator(PathScopeId("")))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if scopeId != "7a7a37b1" {
+		t.Errorf("Expected path based scope ID, got %s", scopeId)
+	}
+}
+
+func TestVueScopeIdCollision(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"A.vue", "B.vue"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(`<template><div>Test</div></template>`), 0644); err != nil {
+			t.Fatalf("Failed to create Vue file: %v", err)
+		}
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import A from './A.vue'; import B from './B.vue'; console.log(A, B);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+	})
+	generator := func(filePath, source string, buildOptions *api.BuildOptions) (string, error) {
+		return "same", nil
+	}
+
+	ctx, ctxErr := api.Context(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithScopeIdGenerator(generator))},
+	})
+	if ctxErr != nil {
+		t.Fatalf("Failed to create build context: %v", ctxErr)
+	}
+	defer ctx.Dispose()
+
+	// The collision is reported on every build, IDs are not carried over between rebuilds
+	for i := 0; i < 2; i++ {
+		result := ctx.Rebuild()
+		if len(result.Errors) > 0 {
+			t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+		}
+		collisions := 0
+		for _, warning := range result.Warnings {
+			if strings.Contains(warning.Text, `Scope ID "same" is also used by`) {
+				collisions++
+			}
+		}
+		if collisions != 1 {
+			t.Errorf("Build %d: expected 1 collision warning, got %d: %v", i, collisions, result.Warnings)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// base64VLQChars is the alphabet used by the source map "mappings" field.
+const base64VLQChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// appendInlineSourceMap appends a source map to JavaScript code as an inline data URL comment.
+// esbuild reads the comment and chains the map into the final bundle source map.
+func appendInlineSourceMap(code string, sourceMap interface{}) (string, error) {
+	dataURL, err := sourceMapDataURL(sourceMap)
+	if err != nil {
+		return "", err
+	}
+	return code + "\n\n//# sourceMappingURL=" + dataURL, nil
+}
+
+// appendInlineCssSourceMap appends a source map to CSS code as an inline data URL comment.
+// CSS has no line comments, so the block comment form of the annotation is used.
+func appendInlineCssSourceMap(code string, sourceMap interface{}) (string, error) {
+	dataURL, err := sourceMapDataURL(sourceMap)
+	if err != nil {
+		return "", err
+	}
+	return code + "\n\n/*# sourceMappingURL=" + dataURL + " */", nil
+}
+
+// cssSourceMapCommentPrefix starts the inline source map comments of CSS, with or without charset
+const cssSourceMapCommentPrefix = "/*# sourceMappingURL=data:application/json;"
+
+// splitInlineCssSourceMap removes the inline source map comment ending CSS code and returns the code and the map as JSON.
+// ok is false, and the code is returned unchanged, if the code doesn't end with an inline source map.
+func splitInlineCssSourceMap(code string) (string, string, bool) {
+	index := strings.LastIndex(code, cssSourceMapCommentPrefix)
+	if index < 0 {
+		return code, "", false
+	}
+	comment := strings.TrimSpace(code[index+len(cssSourceMapCommentPrefix):])
+	_, encoded, found := strings.Cut(strings.TrimSuffix(comment, "*/"), "base64,")
+	if !found || !strings.HasSuffix(comment, "*/") {
+		return code, "", false
+	}
+	sourceMap, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || !json.Valid(sourceMap) {
+		return code, "", false
+	}
+	return strings.TrimRight(code[:index], "\n"), string(sourceMap), true
+}
+
+// sourceMapDataURL encodes a source map as a base64 data URL.
+// The map can be a decoded JSON object or an already serialized JSON string.
+func sourceMapDataURL(sourceMap interface{}) (string, error) {
+	var sourceMapJSON []byte
+	if s, ok := sourceMap.(string); ok {
+		sourceMapJSON = []byte(s)
+	} else {
+		var err error
+		if sourceMapJSON, err = json.Marshal(sourceMap); err != nil {
+			return "", err
+		}
+	}
+	return "data:application/json;charset=utf-8;base64," + base64.StdEncoding.EncodeToString(sourceMapJSON), nil
+}
+
+// encodeVLQ encodes a single integer as a base64 VLQ value used in source map mappings.
+func encodeVLQ(value int) string {
+	// The sign is stored in the least significant bit
+	vlq := value << 1
+	if value < 0 {
+		vlq = (-value << 1) | 1
+	}
+
+	var sb strings.Builder
+	for {
+		digit := vlq & 31
+		vlq >>= 5
+		if vlq > 0 {
+			// Set the continuation bit when more digits follow
+			digit |= 32
+		}
+		sb.WriteByte(base64VLQChars[digit])
+		if vlq == 0 {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// blockLine extracts the 1-based start line of an SFC block from the compilation result.
+// Returns 1 (the top of the file) if the compiler did not report a line.
+func blockLine(block map[string]interface{}) int {
+	if line, ok := toInt(block["line"]); ok && line > 0 {
+		return line
+	}
+	return 1
+}
+
+// buildEntrySourceMap builds a line-level source map for the generated entry module of a Vue SFC.
+// origins holds the .vue line of each generated line that deals with an SFC block, as recorded by
+// generateEntryContents: script imports and exports point at the <script> block, render imports and
+// assignments at the <template> block and style imports at the matching <style> block.
+// Any other line points at the top of the .vue file.
+func buildEntrySourceMap(filePath, source, contents string, origins map[int]int) map[string]interface{} {
+	lines := strings.Split(contents, "\n")
+	segments := make([]string, len(lines))
+	previousLine := 0
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// Lines without a recorded block point at the top of the file
+		originLine, ok := origins[i]
+		if !ok {
+			originLine = 1
+		}
+
+		// Segment fields: generated column, source index, source line delta, source column
+		segments[i] = "A" + "A" + encodeVLQ(originLine-1-previousLine) + "A"
+		previousLine = originLine - 1
+	}
+
+	return map[string]interface{}{
+		"version":        3,
+		"sources":        []string{toPosixPath(filePath)},
+		"sourcesContent": []string{source},
+		"names":          []string{},
+		"mappings":       strings.Join(segments, ";"),
+	}
+}
@@ -0,0 +1,437 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// TestEncodeVLQ tests base64 VLQ encoding of source map values
+func TestEncodeVLQ(t *testing.T) {
+	tests := []struct {
+		input    int
+		expected string
+	}{
+		{0, "A"},
+		{1, "C"},
+		{-1, "D"},
+		{15, "e"},
+		{16, "gB"},
+		{-16, "hB"},
+		{123, "2H"},
+	}
+
+	for _, test := range tests {
+		if result := encodeVLQ(test.input); result != test.expected {
+			t.Errorf("encodeVLQ(%d) = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+}
+
+// TestAppendInlineSourceMap tests appending an inline source map comment
+func TestAppendInlineSourceMap(t *testing.T) {
+	sourceMap := map[string]interface{}{"version": 3, "sources": []string{"test.vue"}, "mappings": "AAAA"}
+	code, err := appendInlineSourceMap("export default {}", sourceMap)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	prefix := "//# sourceMappingURL=data:application/json;charset=utf-8;base64,"
+	index := strings.Index(code, prefix)
+	if index < 0 || !strings.HasPrefix(code, "export default {}") {
+		t.Fatalf("Expected inline sourcemap comment, got: %s", code)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(code[index+len(prefix):])
+	if err != nil {
+		t.Fatalf("Failed to decode sourcemap: %v", err)
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(decoded, &parsed); err != nil {
+		t.Fatalf("Failed to parse sourcemap: %v", err)
+	}
+	if parsed["mappings"] != "AAAA" {
+		t.Errorf("Expected mappings to round-trip, got %v", parsed["mappings"])
+	}
+
+	// Values that can't be marshaled must be reported
+	if _, err := appendInlineSourceMap("", map[string]interface{}{"bad": make(chan int)}); err == nil {
+		t.Error("Expected marshal error for invalid sourcemap")
+	}
+}
+
+// TestBuildEntrySourceMap tests the line mapping of the generated entry module
+func TestBuildEntrySourceMap(t *testing.T) {
+	contents := strings.Join([]string{
+		"import script from 'test.vue?type=script'",
+		"",
+		"import { render } from 'test.vue?type=template'",
+		"import 'test.vue?type=style&index=0'",
+		"script.__file = \"render.vue\";",
+		"export default script;",
+	}, "\n")
+
+	sourceMap := buildEntrySourceMap("/src/test.vue", "<template></template>", contents, map[int]int{0: 5, 2: 1, 3: 10})
+
+	// script -> line 5, empty, template -> line 1, style -> line 10, anything else -> line 1
+	if sourceMap["mappings"] != "AAIA;;AAJA;AASA;AATA;AAAA" {
+		t.Errorf("Unexpected mappings: %v", sourceMap["mappings"])
+	}
+	if sources := sourceMap["sources"].([]string); sources[0] != "/src/test.vue" {
+		t.Errorf("Unexpected sources: %v", sources)
+	}
+	if content := sourceMap["sourcesContent"].([]string); content[0] != "<template></template>" {
+		t.Errorf("Unexpected sources content: %v", content)
+	}
+}
+
+// TestGenerateEntryContentsOrigins tests that the generated lines dealing with SFC blocks are recorded
+func TestGenerateEntryContentsOrigins(t *testing.T) {
+	for _, ssr := range []bool{false, true} {
+		contents, origins, err := generateEntryContents(&entryOptions{
+			filePath: "test.vue",
+			dataId:   "data-v-123",
+			isSSR:    ssr,
+			script:   map[string]interface{}{"content": "export default {}", "line": float64(7)},
+			template: map[string]interface{}{"code": "export function render() {}", "line": float64(2)},
+			styles: []map[string]interface{}{
+				{"code": ".a {}", "scoped": false, "line": float64(12)},
+				{"code": ".b {}", "scoped": true, "line": float64(15)},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to generate entry contents: %v", err)
+		}
+
+		expected := map[string]int{
+			"import script from 'test.vue?type=script'":          7,
+			"export * from 'test.vue?type=script'":               7,
+			"import 'test.vue?type=style&index=0'":               12,
+			"import 'test.vue?type=style&index=1'":               15,
+			"script.render = render;":                            2,
+			"script.ssrRender = ssrRender;":                      2,
+			"import { render } from 'test.vue?type=template'":    2,
+			"import { ssrRender } from 'test.vue?type=template'": 2,
+		}
+		lines := strings.Split(contents, "\n")
+		for i, line := range lines {
+			want, isBlockLine := expected[line]
+			got, recorded := origins[i]
+			if isBlockLine && (!recorded || got != want) {
+				t.Errorf("Expected %q to map to line %d, got %d (recorded %v)", line, want, got, recorded)
+			}
+			if !isBlockLine && recorded {
+				t.Errorf("Expected %q to have no block, got line %d", line, got)
+			}
+		}
+		if len(origins) != 6 {
+			t.Errorf("Expected 6 block lines, got %v", origins)
+		}
+	}
+}
+
+// TestBlockLine tests reading block start lines from compilation results
+func TestBlockLine(t *testing.T) {
+	if line := blockLine(map[string]interface{}{"line": float64(7)}); line != 7 {
+		t.Errorf("Expected line 7, got %d", line)
+	}
+	if line := blockLine(map[string]interface{}{}); line != 1 {
+		t.Errorf("Expected fallback line 1, got %d", line)
+	}
+	if line := blockLine(nil); line != 1 {
+		t.Errorf("Expected fallback line 1 for nil block, got %d", line)
+	}
+}
+
+// TestVueTemplateHandlerWithSourcemap tests that template and entry sourcemaps are accepted by esbuild
+func TestVueTemplateHandlerWithSourcemap(t *testing.T) {
+	mockConfig := &MockEngineConfig{
+		Script: &MockScriptConfig{Content: "export default { name: 'TestComponent' }", Lang: "js"},
+		Template: &MockTemplateConfig{
+			Code: "export function render() { return 'TemplateRender'; }",
+			Map: map[string]interface{}{
+				"version":        3,
+				"sources":        []string{"test.vue"},
+				"sourcesContent": []string{"<template><div>Test</div></template>"},
+				"names":          []string{},
+				"mappings":       "AAAA",
+			},
+		},
+	}
+
+	content := `<template><div>Test</div></template><script>export default { name: 'Test' }</script>`
+	tmpFile := createTempVueFile(t, content)
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	entryContent := fmt.Sprintf(`import App from '%s'; console.log(App);`, filepath.Base(tmpFile))
+	if err := os.WriteFile(entryFile, []byte(entryContent), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(mockConfig)))
+	if err != nil {
+		t.Fatalf("Failed to create JS executor: %v", err)
+	}
+	if err := jsExec.Start(); err != nil {
+		t.Fatalf("Failed to start JS executor: %v", err)
+	}
+	defer jsExec.Stop()
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints: []string{entryFile},
+		Bundle:      true,
+		Write:       false,
+		LogLevel:    api.LogLevelError,
+		Plugins:     []api.Plugin{NewPlugin(WithJsExecutor(jsExec))},
+		Sourcemap:   api.SourceMapInline,
+	})
+
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if len(result.OutputFiles) == 0 {
+		t.Fatal("Expected output files, got none")
+	}
+
+	output := string(result.OutputFiles[0].Contents)
+	if !strings.Contains(output, "TemplateRender") {
+		t.Error("Expected output to contain the render function")
+	}
+	if !strings.Contains(output, "//# sourceMappingURL=data:application/json;base64,") {
+		t.Error("Expected output to contain an inline sourcemap")
+	}
+}
+
+// TestAppendInlineCssSourceMap tests appending inline source maps to CSS
+func TestAppendInlineCssSourceMap(t *testing.T) {
+	prefix := "/*# sourceMappingURL=data:application/json;charset=utf-8;base64,"
+
+	// Serialized JSON strings are embedded as-is
+	sourceMapJSON := `{"version":3,"sources":["/src/main.scss"],"mappings":"AAAA"}`
+	code, err := appendInlineCssSourceMap(".a { color: red; }", sourceMapJSON)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	index := strings.Index(code, prefix)
+	if index < 0 || !strings.HasSuffix(code, " */") {
+		t.Fatalf("Expected CSS sourcemap comment, got: %s", code)
+	}
+	decoded, _ := base64.StdEncoding.DecodeString(strings.TrimSuffix(code[index+len(prefix):], " */"))
+	if string(decoded) != sourceMapJSON {
+		t.Errorf("Expected sourcemap to be embedded unchanged, got: %s", decoded)
+	}
+
+	// Decoded objects are marshaled first
+	code, err = appendInlineCssSourceMap(".a {}", map[string]interface{}{"version": 3})
+	if err != nil || !strings.Contains(code, prefix) {
+		t.Errorf("Expected CSS sourcemap comment, got: %s (%v)", code, err)
+	}
+
+	if _, err := appendInlineCssSourceMap("", make(chan int)); err == nil {
+		t.Error("Expected marshal error for invalid sourcemap")
+	}
+}
+
+// TestCompileSassSourceMap tests that Sass source maps are only returned when requested
+func TestCompileSassSourceMap(t *testing.T) {
+	sourceMapJSON := `{"version":3,"sources":["/test/app.scss"],"mappings":"AAAA"}`
+	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(&MockEngineConfig{
+		Sass: &MockSassConfig{CSS: ".a { color: red; }", Map: sourceMapJSON},
+	})))
+	if err != nil {
+		t.Fatalf("Failed to create JS executor: %v", err)
+	}
+	if err := jsExec.Start(); err != nil {
+		t.Fatalf("Failed to start JS executor: %v", err)
+	}
+	defer jsExec.Stop()
+
+	result, err := compileSass("/test/app.scss", ".a { color: red; }", true, jsExec, nil)
+	if err != nil || result.sourceMap != sourceMapJSON {
+		t.Errorf("Expected sourcemap %q, got %+v (%v)", sourceMapJSON, result, err)
+	}
+
+	result, err = compileSass("/test/app.scss", ".a { color: red; }", false, jsExec, nil)
+	if err != nil || result.sourceMap != "" {
+		t.Errorf("Expected no sourcemap, got %+v (%v)", result, err)
+	}
+}
+
+// TestSassSourceMapOutput tests that Sass source maps are chained into the CSS bundle map
+func TestSassSourceMapOutput(t *testing.T) {
+	tmpFile := createTempSassFile(t, "@use 'partial';\n.button { color: red; }", "scss")
+	partialFile := filepath.Join(filepath.Dir(tmpFile), "_partial.scss")
+	sourceMapJSON := fmt.Sprintf(`{"version":3,"sources":[%q,%q],"sourcesContent":["",""],"names":[],"mappings":"AAAA;ACAA"}`,
+		toPosixPath(tmpFile), toPosixPath(partialFile))
+
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import '%s';`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(&MockEngineConfig{
+		Sass: &MockSassConfig{CSS: ".partial {}\n.button { color: red; }", Map: sourceMapJSON},
+	})))
+	if err != nil {
+		t.Fatalf("Failed to create JS executor: %v", err)
+	}
+	if err := jsExec.Start(); err != nil {
+		t.Fatalf("Failed to start JS executor: %v", err)
+	}
+	defer jsExec.Stop()
+
+	result := buildSassTest(t, entryFile, jsExec, func(options *api.BuildOptions) {
+		options.Sourcemap = api.SourceMapExternal
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	foundMap := false
+	for _, file := range result.OutputFiles {
+		if strings.HasSuffix(file.Path, ".css.map") {
+			foundMap = true
+			if !strings.Contains(string(file.Contents), "_partial.scss") {
+				t.Errorf("Expected CSS sourcemap to reference the Sass partial, got: %s", file.Contents)
+			}
+		}
+	}
+	if !foundMap {
+		t.Error("Expected CSS sourcemap output file, got none")
+	}
+}
+
+// TestVueStyleSourceMapOutput tests that SFC style source maps are chained into the CSS bundle map
+func TestVueStyleSourceMapOutput(t *testing.T) {
+	content := "<template><div class=\"a\">Test</div></template>\n\n<style lang=\"scss\">\n.a { color: red; }\n</style>"
+	tmpFile := createTempVueFile(t, content)
+	mockConfig := &MockEngineConfig{
+		Script:   &MockScriptConfig{Content: "export default { name: 'Test' }", Lang: "js"},
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		Styles: []*MockStyleConfig{{
+			Code:   ".a { color: red; }",
+			Scoped: false,
+			Map: map[string]interface{}{
+				"version":        3,
+				"sources":        []string{toPosixPath(tmpFile)},
+				"sourcesContent": []string{content},
+				"names":          []string{},
+				"mappings":       "AAGA",
+			},
+		}},
+	}
+
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import App from '%s'; console.log(App);`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(mockConfig)))
+	if err != nil {
+		t.Fatalf("Failed to create JS executor: %v", err)
+	}
+	if err := jsExec.Start(); err != nil {
+		t.Fatalf("Failed to start JS executor: %v", err)
+	}
+	defer jsExec.Stop()
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints: []string{entryFile},
+		Bundle:      true,
+		Write:       false,
+		LogLevel:    api.LogLevelError,
+		Outdir:      t.TempDir(),
+		Plugins:     []api.Plugin{NewPlugin(WithJsExecutor(jsExec))},
+		Sourcemap:   api.SourceMapExternal,
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	foundMap := false
+	for _, file := range result.OutputFiles {
+		if strings.HasSuffix(file.Path, ".css.map") {
+			foundMap = true
+			if !strings.Contains(string(file.Contents), filepath.Base(tmpFile)) {
+				t.Errorf("Expected CSS sourcemap to reference the .vue file, got: %s", file.Contents)
+			}
+		}
+	}
+	if !foundMap {
+		t.Error("Expected CSS sourcemap output file, got none")
+	}
+}
+
+func TestTemplateSourceMapWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue": "<script>\nexport default { name: 'App' }\n</script>\n\n<template>\n  <div class=\"app\">{{ message }}</div>\n</template>",
+		"main.js": `import App from './App.vue'; console.log(App);`,
+	})
+
+	result := buildWithCompiler(t, tmpDir, "main.js", func(buildOptions *api.BuildOptions) {
+		buildOptions.Sourcemap = api.SourceMapExternal
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	// The compiled template and the entry module both map back to the .vue file
+	var sourceMap struct {
+		Sources        []string `json:"sources"`
+		SourcesContent []string `json:"sourcesContent"`
+	}
+	if err := json.Unmarshal([]byte(outputFile(result, ".map")), &sourceMap); err != nil {
+		t.Fatalf("Failed to parse sourcemap: %v", err)
+	}
+	found := false
+	for i, source := range sourceMap.Sources {
+		if strings.HasSuffix(source, "App.vue") && i < len(sourceMap.SourcesContent) && strings.Contains(sourceMap.SourcesContent[i], `<div class="app">`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected the sourcemap to reference App.vue, got sources %v", sourceMap.Sources)
+	}
+}
+
+func TestStyleSourceMapWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue":    "<template><div class=\"app\"></div></template>\n\n<style lang=\"scss\">\n$color: red;\n.app { color: $color; }\n</style>",
+		"theme.scss": "$size: 2px;\n.theme { border: $size solid; }",
+		"main.js":    `import App from './App.vue'; import './theme.scss'; console.log(App);`,
+	})
+
+	result := buildWithCompiler(t, tmpDir, "main.js", func(buildOptions *api.BuildOptions) {
+		buildOptions.Sourcemap = api.SourceMapExternal
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	// Style blocks map to the .vue file and standalone Sass files to themselves
+	var cssMap string
+	for _, file := range result.OutputFiles {
+		if strings.HasSuffix(file.Path, ".css.map") {
+			cssMap = string(file.Contents)
+		}
+	}
+	for _, source := range []string{"App.vue", "theme.scss"} {
+		if !strings.Contains(cssMap, source) {
+			t.Errorf("Expected the CSS sourcemap to reference %s, got: %s", source, cssMap)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+	"github.com/rs/xid"
+)
+
+// SsrEntryGlobalName is the global name the server bundle loaded by NewSsrRenderer must be built with,
+// using api.FormatIIFE. The entry of the bundle exports createApp(url, ssrContext), returning the app
+// (or an object with an app property, or a Promise of either), and renderToString re-exported from
+// vue/server-renderer.
+const SsrEntryGlobalName = "__VUE_SSR_ENTRY__"
+
+// ssrRenderService is the global function rendering a page with the server bundle
+const ssrRenderService = "__VUE_SSR_RENDER__"
+
+// ssrRenderScript defines the render service, it runs after the server bundle in every engine.
+// Only JSON values of the SSR context are returned, internal properties of Vue start with "__".
+// It is a declaration so the script completes without a value, the engine doesn't free the values
+// init scripts complete with, and a leaked function makes QuickJS abort when the runtime is freed.
+const ssrRenderScript = `async function ` + ssrRenderService + `(url, context) {
+  const entry = globalThis.` + SsrEntryGlobalName + `;
+  if (!entry || typeof entry.createApp !== 'function' || typeof entry.renderToString !== 'function') {
+    throw new Error('the server bundle must be built with globalName "` + SsrEntryGlobalName + `" and export createApp and renderToString');
+  }
+  const ssrContext = Object.assign({}, context);
+  let app = await entry.createApp(url, ssrContext);
+  if (app && app.app) app = app.app;
+  const html = await entry.renderToString(app, ssrContext);
+  const state = {};
+  for (const key of Object.keys(ssrContext)) {
+    if (key === 'modules' || key === 'teleports' || key.startsWith('__')) continue;
+    try {
+      state[key] = JSON.parse(JSON.stringify(ssrContext[key]));
+    } catch (e) {}
+  }
+  return {
+    html,
+    teleports: ssrContext.teleports || {},
+    modules: ssrContext.modules ? Array.from(ssrContext.modules) : [],
+    context: state,
+  };
+}
+`
+
+// SsrRenderResult holds a page rendered by an SsrRenderer.
+type SsrRenderResult struct {
+	Html      string                 // Rendered HTML of the app, to be inserted into the app container
+	Teleports map[string]string      // Rendered content of <Teleport> components, keyed by their target
+	Modules   []string               // Module IDs of the rendered components, see SsrManifest.PreloadLinks
+	Context   map[string]interface{} // JSON values of the SSR context after rendering, e.g. state to hydrate
+}
+
+// SsrRenderer renders Vue apps to HTML in process, with a server bundle loaded into JS engines.
+type SsrRenderer struct {
+	jsExecutor *jsexecutor.JsExecutor
+}
+
+// NewSsrRenderer creates an SsrRenderer running the server bundle in engines created by engineFactory,
+// such as quickjsengine.NewFactory() from github.com/buke/js-executor/engines/quickjs-go.
+// The bundle is the output of a server build with api.FormatIIFE and SsrEntryGlobalName as GlobalName.
+// Additional executor options, e.g. the pool size, are passed to the JS executor.
+func NewSsrRenderer(bundle string, engineFactory jsexecutor.JsEngineFactory, options ...func(*jsexecutor.JsExecutor)) (*SsrRenderer, error) {
+	initScripts := []*jsexecutor.InitScript{
+		{Content: bundle, FileName: "ssr-bundle.js"},
+		{Content: ssrRenderScript, FileName: "ssr-render.js"},
+	}
+
+	// Step 1: Load the bundle into a single engine first, the engines of the executor are
+	// initialized in the background and an engine that fails to load is not reported by Start
+	if err := checkSsrBundle(initScripts, engineFactory); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Start the engines rendering the pages
+	executorOptions := []func(*jsexecutor.JsExecutor){jsexecutor.WithJsEngine(engineFactory)}
+	executorOptions = append(executorOptions, options...)
+	executorOptions = append(executorOptions, jsexecutor.WithInitScripts(initScripts...))
+
+	jsExecutor, err := jsexecutor.NewExecutor(executorOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SSR executor: %w", err)
+	}
+	if err := jsExecutor.Start(); err != nil {
+		jsExecutor.Stop()
+		return nil, fmt.Errorf("failed to load server bundle: %w", err)
+	}
+	return &SsrRenderer{jsExecutor: jsExecutor}, nil
+}
+
+// checkSsrBundle loads the init scripts of the renderer into an engine and closes it.
+// The OS thread is locked like in the threads of the executor, JS engines must not switch threads.
+func checkSsrBundle(initScripts []*jsexecutor.InitScript, engineFactory jsexecutor.JsEngineFactory) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	engine, err := engineFactory()
+	if err != nil {
+		return fmt.Errorf("failed to create SSR engine: %w", err)
+	}
+	defer engine.Close()
+	if err := engine.Init(initScripts); err != nil {
+		return fmt.Errorf("failed to load server bundle: %w", err)
+	}
+	return nil
+}
+
+// RenderToString renders the page at url with @vue/server-renderer.
+// The values of ctx are copied into the SSR context passed to createApp and renderToString.
+func (r *SsrRenderer) RenderToString(url string, ctx map[string]interface{}) (*SsrRenderResult, error) {
+	jsResponse, err := r.jsExecutor.Execute(&jsexecutor.JsRequest{
+		Id:      xid.New().String(),
+		Service: ssrRenderService,
+		Args:    []interface{}{url, ctx},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", url, err)
+	}
+
+	result, ok := jsResponse.Result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid response from SSR render service")
+	}
+	appHtml, ok := result["html"].(string)
+	if !ok {
+		return nil, fmt.Errorf("failed to extract HTML from render result")
+	}
+
+	rendered := &SsrRenderResult{
+		Html:      appHtml,
+		Teleports: make(map[string]string),
+		Modules:   toStringSlice(result["modules"]),
+	}
+	if teleports, ok := result["teleports"].(map[string]interface{}); ok {
+		for target, content := range teleports {
+			if content, ok := content.(string); ok {
+				rendered.Teleports[target] = content
+			}
+		}
+	}
+	rendered.Context, _ = result["context"].(map[string]interface{})
+	return rendered, nil
+}
+
+// Close stops the JS engines of the renderer.
+func (r *SsrRenderer) Close() error {
+	return r.jsExecutor.Stop()
+}
+
+// SsrManifest maps the module ID of every component, its path relative to the working directory,
+// to the public URLs of the client chunks and stylesheets it is bundled in.
+// The components used while rendering a page are reported by the server build in ssrContext.modules,
+// the manifest turns them into the files the browser should preload.
+type SsrManifest map[string][]string
+
+// LoadSsrManifest reads an SSR manifest written by WriteSsrManifest.
+func LoadSsrManifest(file string) (SsrManifest, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSR manifest: %w", err)
+	}
+	var manifest SsrManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse SSR manifest %s: %w", file, err)
+	}
+	return manifest, nil
+}
+
+// Files returns the client files of the given modules, without duplicates and in the order they are first used.
+func (m SsrManifest) Files(modules []string) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, module := range modules {
+		for _, file := range m[module] {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// PreloadLinks renders the <link> tags preloading the client files of the given modules,
+// to be inserted into the <head> of a server rendered page.
+// Scripts are preloaded as module scripts and stylesheets are applied right away.
+func (m SsrManifest) PreloadLinks(modules []string) string {
+	var links strings.Builder
+	for _, file := range m.Files(modules) {
+		href := html.EscapeString(file)
+		switch path.Ext(strings.SplitN(file, "?", 2)[0]) {
+		case ".js", ".mjs":
+			links.WriteString(`<link rel="modulepreload" crossorigin href="` + href + `">`)
+		case ".css":
+			links.WriteString(`<link rel="stylesheet" href="` + href + `">`)
+		default:
+			continue
+		}
+		links.WriteString("\n")
+	}
+	return links.String()
+}
+
+// WriteSsrManifest returns an OnEndProcessor writing the SSR manifest of the client build to outFile.
+// Use it in the client build, the server build of the same tree registers the module IDs of the
+// components it renders in ssrContext.modules, which are looked up in the manifest.
+// The output paths are relative to Outdir (or the directory of Outfile) and prefixed with PublicPath, or "/".
+//
+// Example usage:
+//
+//	vueplugin.WithOnEndProcessor(vueplugin.WriteSsrManifest("dist/client/ssr-manifest.json"))
+func WriteSsrManifest(outFile string) OnEndProcessor {
+	return func(result *api.BuildResult, buildOptions *api.BuildOptions) error {
+		// Failed builds have no metafile, their errors are already reported
+		if len(result.Errors) > 0 {
+			return nil
+		}
+
+		// Step 1: Map the components to the client files from the build metafile
+		manifest, err := buildSsrManifest(result.Metafile, buildOptions)
+		if err != nil {
+			return err
+		}
+
+		// Step 2: Encode the manifest, map keys are sorted to keep the file stable across builds
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode SSR manifest: %w", err)
+		}
+
+		// Step 3: Write the manifest next to the client build
+		if err := os.MkdirAll(filepath.Dir(outFile), 0755); err != nil {
+			return fmt.Errorf("failed to create output dir for %s: %w", outFile, err)
+		}
+		if err := os.WriteFile(outFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write SSR manifest %s: %w", outFile, err)
+		}
+		return nil
+	}
+}
+
+// buildSsrManifest maps every component bundled in the build to its output files, based on the metafile.
+// A component is listed with the chunks containing it, the chunks statically imported by them and their stylesheets.
+func buildSsrManifest(metafile string, buildOptions *api.BuildOptions) (SsrManifest, error) {
+	if metafile == "" {
+		return nil, fmt.Errorf("metafile is required to write the SSR manifest")
+	}
+	var meta struct {
+		Outputs map[string]struct {
+			Inputs  map[string]interface{} `json:"inputs"`
+			Imports []struct {
+				Path string `json:"path"`
+				Kind string `json:"kind"`
+			} `json:"imports"`
+			CssBundle string `json:"cssBundle"`
+		} `json:"outputs"`
+	}
+	if err := json.Unmarshal([]byte(metafile), &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse metafile: %w", err)
+	}
+
+	files := make(map[string]map[string]bool)
+	for outputPath, output := range meta.Outputs {
+		if path.Ext(outputPath) == ".map" {
+			continue
+		}
+
+		// Files loaded together with the output
+		outputFiles := []string{outputPath}
+		for _, imported := range output.Imports {
+			if imported.Kind == "import-statement" {
+				outputFiles = append(outputFiles, imported.Path)
+			}
+		}
+		if output.CssBundle != "" {
+			outputFiles = append(outputFiles, output.CssBundle)
+		}
+
+		for input := range output.Inputs {
+			module, ok := ssrManifestModule(input, buildOptions)
+			if !ok {
+				continue
+			}
+			if files[module] == nil {
+				files[module] = make(map[string]bool)
+			}
+			for _, file := range outputFiles {
+				files[module][file] = true
+			}
+		}
+	}
+
+	manifest := make(SsrManifest, len(files))
+	for module, moduleFiles := range files {
+		urls := make([]string, 0, len(moduleFiles))
+		for file := range moduleFiles {
+			urls = append(urls, ssrManifestUrl(file, buildOptions))
+		}
+		sort.Strings(urls)
+		manifest[module] = urls
+	}
+	return manifest, nil
+}
+
+// ssrManifestModule returns the module ID of the component a metafile input belongs to.
+// Component parts are loaded in their own namespaces and with a query, and are all mapped to the component.
+func ssrManifestModule(input string, buildOptions *api.BuildOptions) (string, bool) {
+	filePath := input
+	if _, rest, found := strings.Cut(input, ":"); found && !filepath.IsAbs(input) {
+		filePath = rest
+	}
+	filePath = strings.SplitN(filePath, "?", 2)[0]
+	if !strings.HasSuffix(filePath, ".vue") {
+		return "", false
+	}
+	module, err := moduleId(resolveMetafilePath(filePath, buildOptions), buildOptions)
+	if err != nil {
+		return "", false
+	}
+	return module, true
+}
+
+// ssrManifestUrl converts an output path of the metafile to the URL it is served from.
+func ssrManifestUrl(outputPath string, buildOptions *api.BuildOptions) string {
+	outDir := buildOptions.Outdir
+	if outDir == "" && buildOptions.Outfile != "" {
+		outDir = filepath.Dir(buildOptions.Outfile)
+	}
+	file := toPosixPath(outputPath)
+	if outDir != "" {
+		if !filepath.IsAbs(outDir) {
+			outDir = resolveMetafilePath(outDir, buildOptions)
+		}
+		if relPath, err := filepath.Rel(outDir, resolveMetafilePath(outputPath, buildOptions)); err == nil {
+			file = toPosixPath(relPath)
+		}
+	}
+	publicPath := buildOptions.PublicPath
+	if publicPath == "" {
+		publicPath = "/"
+	}
+	return strings.TrimSuffix(publicPath, "/") + "/" + file
+}
+
+// moduleId returns the ID of a component, its path relative to AbsWorkingDir (or the current directory)
+// with forward slashes. The ID is the same in the client and the server build of a tree.
+func moduleId(filePath string, buildOptions *api.BuildOptions) (string, error) {
+	root := buildOptions.AbsWorkingDir
+	if root == "" {
+		var err error
+		if root, err = os.Getwd(); err != nil {
+			return "", fmt.Errorf("failed to determine working directory: %w", err)
+		}
+	}
+	relPath, err := filepath.Rel(root, filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to make %s relative to %s: %w", filePath, root, err)
+	}
+	return toPosixPath(relPath), nil
+}
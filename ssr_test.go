@@ -0,0 +1,408 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	quickjsengine "github.com/buke/js-executor/engines/quickjs-go"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestModuleId(t *testing.T) {
+	buildOptions := &api.BuildOptions{AbsWorkingDir: filepath.FromSlash("/project")}
+	id, err := moduleId(filepath.FromSlash("/project/src/components/App.vue"), buildOptions)
+	if err != nil || id != "src/components/App.vue" {
+		t.Errorf("Expected src/components/App.vue, got %s, %v", id, err)
+	}
+
+	// Without AbsWorkingDir the path is relative to the current directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	id, err = moduleId(filepath.Join(wd, "App.vue"), &api.BuildOptions{})
+	if err != nil || id != "App.vue" {
+		t.Errorf("Expected App.vue, got %s, %v", id, err)
+	}
+}
+
+func TestBuildSsrManifest(t *testing.T) {
+	root := filepath.FromSlash("/project")
+	metafile := `{
+		"outputs": {
+			"dist/main-AB12.js": {
+				"inputs": {
+					"src/App.vue": {},
+					"sfc-script:src/App.vue?type=script": {},
+					"sfc-template:src/App.vue?type=template": {},
+					"src/main.js": {}
+				},
+				"imports": [
+					{"path": "dist/chunk-CD34.js", "kind": "import-statement"},
+					{"path": "dist/Page-EF56.js", "kind": "dynamic-import"}
+				],
+				"entryPoint": "src/main.js",
+				"cssBundle": "dist/main-GH78.css"
+			},
+			"dist/main-AB12.js.map": {"inputs": {}},
+			"dist/Page-EF56.js": {
+				"inputs": {"src/pages/Page.vue": {}},
+				"imports": [{"path": "dist/chunk-CD34.js", "kind": "import-statement"}]
+			},
+			"dist/chunk-CD34.js": {"inputs": {"node_modules/vue/index.js": {}}},
+			"dist/main-GH78.css": {
+				"inputs": {"sfc-style:src/App.vue?type=style&index=0": {}}
+			}
+		}
+	}`
+
+	tests := []struct {
+		name         string
+		buildOptions *api.BuildOptions
+		expected     SsrManifest
+	}{
+		{
+			name:         "outdir",
+			buildOptions: &api.BuildOptions{AbsWorkingDir: root, Outdir: "dist"},
+			expected: SsrManifest{
+				"src/App.vue":        {"/chunk-CD34.js", "/main-AB12.js", "/main-GH78.css"},
+				"src/pages/Page.vue": {"/Page-EF56.js", "/chunk-CD34.js"},
+			},
+		},
+		{
+			name:         "public_path",
+			buildOptions: &api.BuildOptions{AbsWorkingDir: root, Outdir: "dist", PublicPath: "https://cdn.example.com/assets/"},
+			expected: SsrManifest{
+				"src/App.vue": {
+					"https://cdn.example.com/assets/chunk-CD34.js",
+					"https://cdn.example.com/assets/main-AB12.js",
+					"https://cdn.example.com/assets/main-GH78.css",
+				},
+				"src/pages/Page.vue": {
+					"https://cdn.example.com/assets/Page-EF56.js",
+					"https://cdn.example.com/assets/chunk-CD34.js",
+				},
+			},
+		},
+		{
+			name:         "outfile",
+			buildOptions: &api.BuildOptions{AbsWorkingDir: root, Outfile: filepath.FromSlash("/project/dist/main.js")},
+			expected: SsrManifest{
+				"src/App.vue":        {"/chunk-CD34.js", "/main-AB12.js", "/main-GH78.css"},
+				"src/pages/Page.vue": {"/Page-EF56.js", "/chunk-CD34.js"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manifest, err := buildSsrManifest(metafile, test.buildOptions)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(manifest, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, manifest)
+			}
+		})
+	}
+
+	if _, err := buildSsrManifest("", &api.BuildOptions{}); err == nil || !strings.Contains(err.Error(), "metafile is required") {
+		t.Errorf("Expected missing metafile error, got: %v", err)
+	}
+	if _, err := buildSsrManifest("{", &api.BuildOptions{}); err == nil || !strings.Contains(err.Error(), "failed to parse metafile") {
+		t.Errorf("Expected metafile parse error, got: %v", err)
+	}
+}
+
+func TestSsrManifestPreloadLinks(t *testing.T) {
+	manifest := SsrManifest{
+		"src/App.vue":        {"/chunk.js", "/main.js", "/main.css"},
+		"src/pages/Page.vue": {"/Page.js", "/chunk.js", "/logo.png"},
+	}
+
+	files := manifest.Files([]string{"src/App.vue", "src/pages/Page.vue", "src/Unknown.vue"})
+	if expected := []string{"/chunk.js", "/main.js", "/main.css", "/Page.js", "/logo.png"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	links := manifest.PreloadLinks([]string{"src/App.vue", "src/pages/Page.vue"})
+	expected := `<link rel="modulepreload" crossorigin href="/chunk.js">
+<link rel="modulepreload" crossorigin href="/main.js">
+<link rel="stylesheet" href="/main.css">
+<link rel="modulepreload" crossorigin href="/Page.js">
+`
+	if links != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, links)
+	}
+
+	if links := manifest.PreloadLinks(nil); links != "" {
+		t.Errorf("Expected no links without modules, got: %s", links)
+	}
+}
+
+func TestLoadSsrManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "ssr-manifest.json")
+	if err := os.WriteFile(file, []byte(`{"src/App.vue": ["/main.js"]}`), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	manifest, err := LoadSsrManifest(file)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(manifest, SsrManifest{"src/App.vue": {"/main.js"}}) {
+		t.Errorf("Unexpected manifest: %v", manifest)
+	}
+
+	if _, err := LoadSsrManifest(filepath.Join(tmpDir, "missing.json")); err == nil {
+		t.Error("Expected error for missing manifest")
+	}
+	if err := os.WriteFile(file, []byte(`[`), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if _, err := LoadSsrManifest(file); err == nil || !strings.Contains(err.Error(), "failed to parse SSR manifest") {
+		t.Errorf("Expected parse error, got: %v", err)
+	}
+}
+
+func TestSsrRendererRenderToString(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *MockEngineConfig
+		expectError string
+	}{
+		{
+			name: "rendered",
+			config: &MockEngineConfig{ServiceResponses: map[string]interface{}{
+				ssrRenderService: map[string]interface{}{
+					"html":      "<div>Home</div>",
+					"teleports": map[string]interface{}{"#modal": "<p>Modal</p>"},
+					"modules":   []interface{}{"src/App.vue", "src/pages/Home.vue"},
+					"context":   map[string]interface{}{"state": map[string]interface{}{"count": float64(1)}},
+				},
+			}},
+		},
+		{
+			name:        "execute_error",
+			config:      &MockEngineConfig{ExecuteError: fmt.Errorf("createApp is not a function")},
+			expectError: "failed to render /: createApp is not a function",
+		},
+		{
+			name:        "invalid_result",
+			config:      &MockEngineConfig{InvalidResult: true},
+			expectError: "invalid response from SSR render service",
+		},
+		{
+			name:        "missing_html",
+			config:      &MockEngineConfig{EmptyResult: true},
+			expectError: "failed to extract HTML",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var request *jsexecutor.JsRequest
+			test.config.OnRequest = func(req *jsexecutor.JsRequest) { request = req }
+			renderer, err := NewSsrRenderer("", NewMockEngineFactory(test.config))
+			if err != nil {
+				t.Fatalf("Failed to create renderer: %v", err)
+			}
+			defer renderer.Close()
+
+			result, err := renderer.RenderToString("/", map[string]interface{}{"locale": "en"})
+			if test.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("Expected error containing '%s', got: %v", test.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if request.Service != ssrRenderService || request.Args[0] != "/" {
+				t.Errorf("Unexpected render request: %+v", request)
+			}
+			expected := &SsrRenderResult{
+				Html:      "<div>Home</div>",
+				Teleports: map[string]string{"#modal": "<p>Modal</p>"},
+				Modules:   []string{"src/App.vue", "src/pages/Home.vue"},
+				Context:   map[string]interface{}{"state": map[string]interface{}{"count": float64(1)}},
+			}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, result)
+			}
+		})
+	}
+}
+
+func TestNewSsrRendererError(t *testing.T) {
+	if _, err := NewSsrRenderer("", NewMockEngineFactoryWithError("engine failed")); err == nil {
+		t.Error("Expected error when the engine can't be created")
+	}
+}
+
+// Integration tests
+
+func TestSsrRendererQuickJs(t *testing.T) {
+	// A server bundle stub, exporting the same API as an entry built with vue/server-renderer
+	bundle := `var ` + SsrEntryGlobalName + ` = (() => {
+		const createApp = async (url, ssrContext) => ({ app: { url, ssrContext } });
+		const renderToString = async (app, ssrContext) => {
+			ssrContext.modules = new Set(['src/App.vue']);
+			ssrContext.teleports = { '#modal': '<p>Modal</p>' };
+			ssrContext.__internal = true;
+			ssrContext.render = () => {};
+			ssrContext.state = { user: ssrContext.user };
+			return '<div>' + app.url + '</div>';
+		};
+		return { createApp, renderToString };
+	})();`
+
+	renderer, err := NewSsrRenderer(bundle, quickjsengine.NewFactory())
+	if err != nil {
+		t.Fatalf("Failed to create renderer: %v", err)
+	}
+	defer renderer.Close()
+
+	result, err := renderer.RenderToString("/about", map[string]interface{}{"user": "alice"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result.Html != "<div>/about</div>" {
+		t.Errorf("Unexpected HTML: %s", result.Html)
+	}
+	if !reflect.DeepEqual(result.Modules, []string{"src/App.vue"}) {
+		t.Errorf("Unexpected modules: %v", result.Modules)
+	}
+	if result.Teleports["#modal"] != "<p>Modal</p>" {
+		t.Errorf("Unexpected teleports: %v", result.Teleports)
+	}
+	if _, exists := result.Context["__internal"]; exists {
+		t.Errorf("Expected internal context properties to be skipped, got: %v", result.Context)
+	}
+	state, _ := result.Context["state"].(map[string]interface{})
+	if state["user"] != "alice" {
+		t.Errorf("Expected context state to be returned, got: %v", result.Context)
+	}
+
+	// A bundle built without the global name is reported when rendering
+	renderer, err = NewSsrRenderer(`var app = {};`, quickjsengine.NewFactory())
+	if err != nil {
+		t.Fatalf("Failed to create renderer: %v", err)
+	}
+	defer renderer.Close()
+	if _, err := renderer.RenderToString("/", nil); err == nil || !strings.Contains(err.Error(), SsrEntryGlobalName) {
+		t.Errorf("Expected missing entry error, got: %v", err)
+	}
+
+	// Errors thrown while loading the bundle are reported when the renderer is created
+	if _, err := NewSsrRenderer(`throw new Error('broken bundle');`, quickjsengine.NewFactory()); err == nil || !strings.Contains(err.Error(), "failed to load server bundle") {
+		t.Errorf("Expected bundle load error, got: %v", err)
+	}
+}
+
+func TestWriteSsrManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	vueFile := filepath.Join(tmpDir, "src", "App.vue")
+	if err := os.MkdirAll(filepath.Dir(vueFile), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(vueFile, []byte(`<template><div>Test</div></template><style>.app { color: red; }</style>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "src", "main.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		Styles:   []*MockStyleConfig{{Code: ".app { color: red; }", Scoped: false}},
+	})
+
+	manifestFile := filepath.Join(tmpDir, "dist", "ssr-manifest.json")
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         true,
+		Outdir:        "dist",
+		EntryNames:    "[name]-[hash]",
+		LogLevel:      api.LogLevelError,
+		AbsWorkingDir: tmpDir,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithOnEndProcessor(WriteSsrManifest(manifestFile)))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	manifest, err := LoadSsrManifest(manifestFile)
+	if err != nil {
+		t.Fatalf("Expected manifest to be written, got: %v", err)
+	}
+	files := manifest["src/App.vue"]
+	if len(files) != 2 {
+		t.Fatalf("Expected the JS and CSS output of src/App.vue, got: %v", manifest)
+	}
+	for _, file := range files {
+		if !strings.HasPrefix(file, "/main-") {
+			t.Errorf("Expected output URL relative to outdir, got %s", file)
+		}
+		if _, err := os.Stat(filepath.Join(tmpDir, "dist", strings.TrimPrefix(file, "/"))); err != nil {
+			t.Errorf("Expected %s to exist in outdir: %v", file, err)
+		}
+	}
+
+	// Failed builds only report their own errors
+	failed := &api.BuildResult{Errors: []api.Message{{Text: "build failed"}}}
+	if err := WriteSsrManifest(manifestFile)(failed, &api.BuildOptions{}); err != nil {
+		t.Errorf("Expected no manifest error for a failed build, got: %v", err)
+	}
+}
+
+func TestVueSsrModules(t *testing.T) {
+	tmpDir := t.TempDir()
+	vueFile := filepath.Join(tmpDir, "src", "App.vue")
+	if err := os.MkdirAll(filepath.Dir(vueFile), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(vueFile, []byte(`<template><div>Test</div></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './src/App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function ssrRender() { return null; }"},
+	})
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		External:      []string{"vue"},
+		Format:        api.FormatESModule,
+		LogLevel:      api.LogLevelError,
+		AbsWorkingDir: tmpDir,
+		Define:        map[string]string{"import.meta.env.SSR": "true"},
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if len(result.OutputFiles) == 0 {
+		t.Fatal("Expected output files")
+	}
+	if output := string(result.OutputFiles[0].Contents); !strings.Contains(output, `"src/App.vue"`) || !strings.Contains(output, "useSSRContext") {
+		t.Errorf("Expected the server build to register src/App.vue in ssrContext.modules, got:\n%s", output)
+	}
+}
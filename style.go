@@ -0,0 +1,302 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+	"github.com/rs/xid"
+)
+
+// StyleMeta describes the origin of CSS passed to an OnStyleProcessor.
+type StyleMeta struct {
+	File   string // Path of the .vue file or standalone stylesheet
+	Index  int    // Index of the <style> block in the .vue file, -1 for standalone stylesheets
+	Scoped bool   // Whether the <style> block is scoped
+	Lang   string // Source language, e.g. "css", "scss", "sass", "less" or "stylus"
+
+	// SourceMap is the source map of the CSS as JSON, empty if source maps are disabled.
+	// Processors changing the CSS keep the mapping to the sources by ending their output with
+	// an inline source map chained to it, like TransformCss. Otherwise the map is discarded.
+	SourceMap string
+}
+
+// processedStyle holds the output of the style processor chain.
+type processedStyle struct {
+	css       string // Processed CSS code
+	sourceMap string // Source map of the processed CSS as JSON, empty if none
+	mapLost   bool   // Whether a processor changed the CSS without a source map, discarding the input map
+}
+
+// stylesheetResult holds the output of a standalone stylesheet compilation (Sass, Less, Stylus).
+type stylesheetResult struct {
+	css       string   // Compiled CSS code
+	sourceMap string   // Source map as JSON string, empty if not generated
+	imports   []string // Files imported by the stylesheet
+}
+
+// stylesheetLoader describes a standalone stylesheet language compiled by a service of the Vue compiler.
+// The service is called with the preprocessor options, the source ("data"), the file name ("filename")
+// and "sourceMap", and returns the compiled "css", its "map" and the "imports" of the stylesheet.
+type stylesheetLoader struct {
+	name      string // Language name used in messages, e.g. "Less"
+	filter    string // esbuild filter matching the file extensions
+	namespace string // Namespace of the resolved stylesheets
+	service   string // Compiler service, e.g. "sfc.less.renderSync"
+}
+
+// setupStylesheetHandler registers the resolve and load handlers of a standalone stylesheet language.
+func setupStylesheetHandler(opts *Options, build *api.PluginBuild, loader stylesheetLoader) {
+	// Register resolve handler for the stylesheets
+	registerStylesheetResolveHandler(opts, build, loader)
+
+	// Register load and compile handler for the stylesheets
+	registerStylesheetLoadHandler(opts, build, loader)
+}
+
+// registerStylesheetResolveHandler registers the path resolution handler for the stylesheets of a loader.
+// It handles TypeScript path aliases and converts relative paths to absolute paths.
+// Resolved stylesheets are assigned to the namespace of the loader for further processing.
+func registerStylesheetResolveHandler(opts *Options, build *api.PluginBuild, loader stylesheetLoader) {
+	build.OnResolve(api.OnResolveOptions{Filter: loader.filter}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
+		// Apply TypeScript path aliases to support imports like @/styles/theme.less
+		path, err := opts.pathAlias.apply(args.Path)
+		if err != nil {
+			opts.logger.Error("Failed to parse tsconfig path aliases", "error", err)
+			return api.OnResolveResult{}, err
+		}
+
+		// Convert relative paths to absolute paths for consistent file resolution
+		if !filepath.IsAbs(path) {
+			path = filepath.Clean(filepath.Join(args.ResolveDir, path))
+		}
+
+		return api.OnResolveResult{
+			Path:      path,
+			Namespace: loader.namespace,
+		}, nil
+	})
+}
+
+// registerStylesheetLoadHandler registers the handler to load and compile the stylesheets of a loader.
+// It reads the source content and compiles it to CSS using the service of the loader.
+// Files imported by the stylesheet are registered as watch dependencies.
+func registerStylesheetLoadHandler(opts *Options, build *api.PluginBuild, loader stylesheetLoader) {
+	build.OnLoad(api.OnLoadOptions{Filter: loader.filter, Namespace: loader.namespace}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
+		// Step 1: Read the stylesheet source content
+		fbyte, err := os.ReadFile(args.Path)
+		if err != nil {
+			err = fmt.Errorf("failed to read %s file: %w", strings.ToLower(loader.name), err)
+			opts.logger.Error("Failed to read "+loader.name+" file", "error", err, "file", args.Path)
+			return api.OnLoadResult{
+				Errors: []api.Message{{
+					Text: err.Error(),
+					Location: &api.Location{
+						File: args.Path,
+					},
+				}},
+			}, err
+		}
+
+		// Step 2: Compile the stylesheet to CSS, source maps are only generated when esbuild emits them
+		result, err := compileStylesheet(loader, args.Path, string(fbyte), build.InitialOptions.Sourcemap > 0, opts.stylePreprocessorOptions, opts.jsExecutor)
+		if err != nil {
+			opts.logger.Error("Failed to compile "+loader.name, "error", err, "file", args.Path)
+			return api.OnLoadResult{
+				Errors: []api.Message{{
+					Text: err.Error(),
+					Location: &api.Location{
+						File: args.Path,
+					},
+				}},
+			}, err
+		}
+
+		// Step 3: Run the style processor chain and attach the source map so esbuild chains it back to the sources
+		meta := StyleMeta{File: args.Path, Index: -1, Lang: stylesheetLang(args.Path)}
+		css, warnings, err := processStylesheet(opts, result.css, result.sourceMap, meta, build.InitialOptions)
+		if err != nil {
+			opts.logger.Error("Failed to process "+loader.name+" output", "error", err, "file", args.Path)
+			return api.OnLoadResult{
+				Errors: []api.Message{{
+					Text: err.Error(),
+					Location: &api.Location{
+						File: args.Path,
+					},
+				}},
+			}, err
+		}
+
+		// Step 4: Return compiled CSS with appropriate loader
+		return api.OnLoadResult{
+			Contents:   &css,
+			Loader:     api.LoaderCSS, // Use CSS loader for the compiled output
+			WatchFiles: result.imports,
+			Warnings:   warnings,
+		}, nil
+	})
+}
+
+// compileStylesheet compiles a standalone stylesheet to CSS using the service of the loader via the JS executor.
+// Imports are resolved relative to the compiled file and to the "paths" preprocessor option.
+// The style preprocessor options are passed to the service, the compiled file can't be overridden by them.
+func compileStylesheet(loader stylesheetLoader, filePath, source string, sourceMap bool, preprocessorOptions map[string]any, jsExecutor *jsexecutor.JsExecutor) (*stylesheetResult, error) {
+	// Preprocessor options come first so the compiled file can't be overridden
+	options := make(map[string]interface{}, len(preprocessorOptions)+3)
+	for k, v := range preprocessorOptions {
+		options[k] = v
+	}
+	options["data"] = source                    // Source code to compile
+	options["filename"] = toPosixPath(filePath) // Base for relative imports and source map name
+	options["sourceMap"] = sourceMap            // Generate source maps only when esbuild emits them
+
+	// Execute the compilation via the service of the Vue compiler
+	name := strings.ToLower(loader.name)
+	jsResponse, err := jsExecutor.Execute(&jsexecutor.JsRequest{
+		Id:      xid.New().String(),
+		Service: loader.service,
+		Args:    []interface{}{options},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s compilation service failed: %w", name, err)
+	}
+
+	// Extract and validate compilation result
+	result, ok := jsResponse.Result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid response from %s compilation service", name)
+	}
+
+	// Extract the compiled CSS code from the result
+	code, ok := result["css"].(string)
+	if !ok {
+		return nil, fmt.Errorf("failed to extract CSS from compilation result")
+	}
+
+	compiled := &stylesheetResult{
+		css:     code,
+		imports: toStringSlice(result["imports"]),
+	}
+	if sourceMap {
+		compiled.sourceMap, _ = result["map"].(string)
+	}
+
+	return compiled, nil
+}
+
+// applyStyleProcessors runs the style processor chain on compiled CSS and its source map, empty if none.
+// Each processor receives the output of the previous one, with the source map of its input in meta.SourceMap.
+func applyStyleProcessors(opts *Options, css, sourceMap string, meta StyleMeta, buildOptions *api.BuildOptions) (*processedStyle, error) {
+	result := &processedStyle{css: css, sourceMap: sourceMap}
+	for _, processor := range opts.onStyleProcessors {
+		meta.SourceMap = result.sourceMap
+		processed, err := processor(result.css, meta, buildOptions)
+		if err != nil {
+			return nil, fmt.Errorf("style processor failed: %w", err)
+		}
+
+		// Processors chaining the source map return it inline, unchanged CSS keeps its map
+		if code, chained, ok := splitInlineCssSourceMap(processed); ok {
+			result.css, result.sourceMap = code, chained
+			continue
+		}
+		if processed != result.css && result.sourceMap != "" {
+			opts.logger.Warn("Style processors changed the CSS, its source map is discarded", "file", meta.File)
+			result.sourceMap = ""
+			result.mapLost = true
+		}
+		result.css = processed
+	}
+	return result, nil
+}
+
+// code returns the processed CSS with its source map attached, so esbuild chains it back to the sources.
+func (s *processedStyle) code() (string, error) {
+	if s.sourceMap == "" {
+		return s.css, nil
+	}
+	return appendInlineCssSourceMap(s.css, s.sourceMap)
+}
+
+// warnings returns the warning reported when a processor discarded the source map.
+func (s *processedStyle) warnings(location *api.Location) []api.Message {
+	if !s.mapLost {
+		return nil
+	}
+	return []api.Message{{
+		Text:     "Style processors changed the CSS without returning a source map, the CSS maps to the compiled output instead of the sources",
+		Location: location,
+	}}
+}
+
+// processStylesheet runs the style processor chain on a compiled standalone stylesheet
+// and attaches its source map. A warning is returned if a processor discarded the source map.
+func processStylesheet(opts *Options, css, sourceMap string, meta StyleMeta, buildOptions *api.BuildOptions) (string, []api.Message, error) {
+	processed, err := applyStyleProcessors(opts, css, sourceMap, meta, buildOptions)
+	if err != nil {
+		return "", nil, err
+	}
+	code, err := processed.code()
+	if err != nil {
+		return "", nil, err
+	}
+	return code, processed.warnings(&api.Location{File: meta.File}), nil
+}
+
+// stylesheetLang returns the StyleMeta language of a standalone stylesheet based on its extension.
+func stylesheetLang(filePath string) string {
+	switch {
+	case strings.HasSuffix(filePath, ".styl"), strings.HasSuffix(filePath, ".stylus"):
+		return "stylus"
+	case strings.HasSuffix(filePath, ".less"):
+		return "less"
+	case strings.HasSuffix(filePath, ".sass"):
+		return "sass"
+	case strings.HasSuffix(filePath, ".scss"):
+		return "scss"
+	default:
+		return "css"
+	}
+}
+
+// TransformCss returns an OnStyleProcessor that runs CSS through esbuild's CSS transform
+// using the Target, Engines and Supported settings of the build.
+// This lowers modern syntax and adds vendor prefixes required by the configured targets.
+// The source map of the input is chained, the output then ends with the resulting inline source map.
+func TransformCss() OnStyleProcessor {
+	return func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+		// esbuild reads the inline source map of its input and maps the output through it
+		sourcemap := api.SourceMapNone
+		if meta.SourceMap != "" {
+			var err error
+			if css, err = appendInlineCssSourceMap(css, meta.SourceMap); err != nil {
+				return "", err
+			}
+			sourcemap = api.SourceMapInline
+		}
+
+		result := api.Transform(css, api.TransformOptions{
+			Loader:     api.LoaderCSS,
+			Sourcefile: meta.File,
+			Sourcemap:  sourcemap,
+			Target:     buildOptions.Target,
+			Engines:    buildOptions.Engines,
+			Supported:  buildOptions.Supported,
+			LogLevel:   api.LogLevelSilent,
+		})
+		if len(result.Errors) > 0 {
+			texts := make([]string, len(result.Errors))
+			for i, msg := range result.Errors {
+				texts[i] = msg.Text
+			}
+			return "", fmt.Errorf("css transform failed: %s", strings.Join(texts, "; "))
+		}
+		return string(result.Code), nil
+	}
+}
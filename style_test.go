@@ -0,0 +1,389 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestApplyStyleProcessors(t *testing.T) {
+	opts := newOptions()
+	WithOnStyleProcessor(func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+		return strings.ReplaceAll(css, "var(--brand)", "#42b883"), nil
+	})(opts)
+	WithOnStyleProcessor(func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+		return css + "/* " + meta.Lang + " */", nil
+	})(opts)
+
+	processed, err := applyStyleProcessors(opts, ".a { color: var(--brand); }", "", StyleMeta{Lang: "scss"}, &api.BuildOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if processed.css != ".a { color: #42b883; }/* scss */" || processed.mapLost {
+		t.Errorf("Expected processors to run in order, got: %+v", processed)
+	}
+
+	WithOnStyleProcessor(func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+		return "", fmt.Errorf("banned property")
+	})(opts)
+	if _, err := applyStyleProcessors(opts, ".a {}", "", StyleMeta{}, &api.BuildOptions{}); err == nil || !strings.Contains(err.Error(), "style processor failed: banned property") {
+		t.Errorf("Expected processor error, got: %v", err)
+	}
+}
+
+func TestProcessStylesheet(t *testing.T) {
+	sourceMap := `{"version":3,"sources":["app.scss"],"mappings":""}`
+
+	t.Run("unchanged_keeps_map", func(t *testing.T) {
+		css, warnings, err := processStylesheet(newOptions(), ".a {}", sourceMap, StyleMeta{}, &api.BuildOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !strings.Contains(css, "/*# sourceMappingURL=data:application/json;charset=utf-8;base64,") || len(warnings) != 0 {
+			t.Errorf("Expected source map to be attached, got: %s, %v", css, warnings)
+		}
+	})
+
+	t.Run("changed_drops_map", func(t *testing.T) {
+		var logs bytes.Buffer
+		opts := newOptions()
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))(opts)
+		WithOnStyleProcessor(func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+			return css + ".b {}", nil
+		})(opts)
+		css, warnings, err := processStylesheet(opts, ".a {}", sourceMap, StyleMeta{File: "app.scss"}, &api.BuildOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if css != ".a {}.b {}" {
+			t.Errorf("Expected processed CSS without source map, got: %s", css)
+		}
+		if !strings.Contains(logs.String(), "source map is discarded") {
+			t.Errorf("Expected a warning for the discarded source map, got: %s", logs.String())
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0].Text, "without returning a source map") || warnings[0].Location.File != "app.scss" {
+			t.Errorf("Expected a build warning for the discarded source map, got: %v", warnings)
+		}
+	})
+
+	t.Run("chained_map", func(t *testing.T) {
+		var metaSourceMap string
+		opts := newOptions()
+		WithOnStyleProcessor(func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+			metaSourceMap = meta.SourceMap
+			return appendInlineCssSourceMap(css+".b {}", `{"version":3,"sources":["chained.scss"],"mappings":""}`)
+		})(opts)
+		WithOnStyleProcessor(func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+			if !strings.Contains(meta.SourceMap, "chained.scss") {
+				return "", fmt.Errorf("expected the chained source map, got %s", meta.SourceMap)
+			}
+			return css, nil
+		})(opts)
+		css, warnings, err := processStylesheet(opts, ".a {}", sourceMap, StyleMeta{}, &api.BuildOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if metaSourceMap != sourceMap || len(warnings) != 0 {
+			t.Errorf("Expected the input source map in meta without warnings, got %s, %v", metaSourceMap, warnings)
+		}
+		code, chained, ok := splitInlineCssSourceMap(css)
+		if !ok || code != ".a {}.b {}" || !strings.Contains(chained, "chained.scss") {
+			t.Errorf("Expected CSS with the chained source map, got: %s", css)
+		}
+	})
+}
+
+func TestStylesheetLang(t *testing.T) {
+	tests := map[string]string{
+		"/a/app.scss":   "scss",
+		"/a/app.sass":   "sass",
+		"/a/app.less":   "less",
+		"/a/app.styl":   "stylus",
+		"/a/app.stylus": "stylus",
+		"/a/app.css":    "css",
+	}
+	for path, expected := range tests {
+		if lang := stylesheetLang(path); lang != expected {
+			t.Errorf("stylesheetLang(%q) = %q, expected %q", path, lang, expected)
+		}
+	}
+}
+
+func TestTransformCss(t *testing.T) {
+	processor := TransformCss()
+
+	css, err := processor(".a { user-select: none; }", StyleMeta{File: "app.css"}, &api.BuildOptions{
+		Engines: []api.Engine{{Name: api.EngineSafari, Version: "12"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.Contains(css, "-webkit-user-select") {
+		t.Errorf("Expected vendor prefix for the configured engines, got: %s", css)
+	}
+	if strings.Contains(css, "sourceMappingURL") {
+		t.Errorf("Expected no source map without an input source map, got: %s", css)
+	}
+
+	// The input source map is chained, the output maps to the original sources
+	sourceMap := `{"version":3,"sources":["/src/app.scss"],"sourcesContent":[".a { user-select: none; }"],"names":[],"mappings":"AAAA,GAAK"}`
+	css, err = processor(".a { user-select: none; }", StyleMeta{File: "app.css", SourceMap: sourceMap}, &api.BuildOptions{
+		Engines: []api.Engine{{Name: api.EngineSafari, Version: "12"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	code, chained, ok := splitInlineCssSourceMap(css)
+	if !ok || !strings.Contains(code, "-webkit-user-select") || strings.Contains(code, "sourceMappingURL") {
+		t.Fatalf("Expected transformed CSS with an inline source map, got: %s", css)
+	}
+	if !strings.Contains(chained, "/src/app.scss") || strings.Contains(chained, "app.css") {
+		t.Errorf("Expected the source map to map to the original sources, got: %s", chained)
+	}
+}
+
+// Integration tests
+
+func TestStyleProcessorOnVueStyles(t *testing.T) {
+	tmpFile := createTempVueFile(t, `<template><div class="a">Test</div></template><style scoped lang="scss">.a { color: red; }</style>`)
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import App from '%s'; console.log(App);`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		Styles: []*MockStyleConfig{
+			{Code: ".a[data-v-1] { color: var(--brand); }", Scoped: true, Lang: "scss"},
+			{Code: ".b { color: var(--brand); }", Scoped: false},
+		},
+	})
+
+	var metas []StyleMeta
+	processor := func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+		metas = append(metas, meta)
+		return strings.ReplaceAll(css, "var(--brand)", "#42b883"), nil
+	}
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints: []string{entryFile},
+		Bundle:      true,
+		Write:       false,
+		LogLevel:    api.LogLevelError,
+		Outdir:      t.TempDir(),
+		Plugins:     []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithOnStyleProcessor(processor))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	if len(metas) != 2 {
+		t.Fatalf("Expected processor to run for 2 style blocks, got %d", len(metas))
+	}
+	for _, meta := range metas {
+		if meta.File != tmpFile {
+			t.Errorf("Expected meta file %s, got %s", tmpFile, meta.File)
+		}
+		switch meta.Index {
+		case 0:
+			if !meta.Scoped || meta.Lang != "scss" {
+				t.Errorf("Unexpected meta for first block: %+v", meta)
+			}
+		case 1:
+			if meta.Scoped || meta.Lang != "css" {
+				t.Errorf("Unexpected meta for second block: %+v", meta)
+			}
+		default:
+			t.Errorf("Unexpected block index: %d", meta.Index)
+		}
+	}
+
+	for _, file := range result.OutputFiles {
+		if strings.HasSuffix(file.Path, ".css") && strings.Contains(string(file.Contents), "var(--brand)") {
+			t.Errorf("Expected tokens to be substituted, got: %s", file.Contents)
+		}
+	}
+}
+
+func TestStyleProcessorOnVueStylesSourceMap(t *testing.T) {
+	tmpFile := createTempVueFile(t, `<template><div class="a">Test</div></template><style>.a { user-select: none; }</style>`)
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import App from '%s'; console.log(App);`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		Styles: []*MockStyleConfig{{
+			Code:   ".a { user-select: none; }",
+			Scoped: false,
+			Map: map[string]interface{}{
+				"version":        3,
+				"sources":        []interface{}{tmpFile},
+				"sourcesContent": []interface{}{".a { user-select: none; }"},
+				"names":          []interface{}{},
+				"mappings":       "AAAA,GAAK",
+			},
+		}},
+	})
+
+	build := func(processor OnStyleProcessor) api.BuildResult {
+		return api.Build(api.BuildOptions{
+			EntryPoints: []string{entryFile},
+			Bundle:      true,
+			Write:       false,
+			LogLevel:    api.LogLevelSilent,
+			Sourcemap:   api.SourceMapExternal,
+			Engines:     []api.Engine{{Name: api.EngineSafari, Version: "12"}},
+			Outdir:      t.TempDir(),
+			Plugins:     []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithOnStyleProcessor(processor))},
+		})
+	}
+	cssMap := func(result api.BuildResult) string {
+		for _, file := range result.OutputFiles {
+			if strings.HasSuffix(file.Path, ".css.map") {
+				return string(file.Contents)
+			}
+		}
+		return ""
+	}
+
+	// TransformCss chains the source map of the <style> block
+	result := build(TransformCss())
+	if len(result.Errors) > 0 || len(result.Warnings) > 0 {
+		t.Fatalf("Expected successful build without warnings, got: %v, %v", result.Errors, result.Warnings)
+	}
+	if sourceMap := cssMap(result); !strings.Contains(sourceMap, filepath.Base(tmpFile)) {
+		t.Errorf("Expected the CSS source map to point to the .vue file, got: %s", sourceMap)
+	}
+
+	// Processors changing the CSS without a source map discard it with a warning
+	result = build(func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+		return css + ".b { color: red; }", nil
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0].Text, "without returning a source map") || result.Warnings[0].Location == nil {
+		t.Errorf("Expected a warning for the discarded source map, got: %v", result.Warnings)
+	}
+}
+
+func TestStyleProcessorOnVueStylesError(t *testing.T) {
+	tmpFile := createTempVueFile(t, "<template><div>Test</div></template>\n<style>.a { float: left; }</style>")
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import App from '%s'; console.log(App);`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		Styles:   []*MockStyleConfig{{Code: ".a { float: left; }", Scoped: false}},
+	})
+	processor := func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+		if strings.Contains(css, "float") {
+			return "", fmt.Errorf("property float is banned")
+		}
+		return css, nil
+	}
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints: []string{entryFile},
+		Bundle:      true,
+		Write:       false,
+		LogLevel:    api.LogLevelSilent,
+		Outdir:      t.TempDir(),
+		Plugins:     []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithOnStyleProcessor(processor))},
+	})
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "property float is banned") {
+		t.Fatalf("Expected processor error, got: %v", result.Errors)
+	}
+	if location := result.Errors[0].Location; location == nil || !strings.HasSuffix(location.File, filepath.Base(tmpFile)) {
+		t.Errorf("Expected error located in the .vue file, got: %+v", location)
+	}
+}
+
+func TestStyleProcessorOnStylesheets(t *testing.T) {
+	tests := []struct {
+		ext        string
+		mockConfig *MockEngineConfig
+	}{
+		{"scss", &MockEngineConfig{Sass: &MockSassConfig{CSS: ".a { color: var(--brand); }"}}},
+		{"less", &MockEngineConfig{ServiceResponses: map[string]interface{}{
+			"sfc.less.renderSync": map[string]interface{}{"css": ".a { color: var(--brand); }"},
+		}}},
+		{"styl", &MockEngineConfig{ServiceResponses: map[string]interface{}{
+			"sfc.stylus.renderSync": map[string]interface{}{"css": ".a { color: var(--brand); }"},
+		}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ext, func(t *testing.T) {
+			tmpFile := createTempSassFile(t, ".a { color: red; }", test.ext)
+			entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+			if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import '%s';`, filepath.Base(tmpFile))), 0644); err != nil {
+				t.Fatalf("Failed to create entry file: %v", err)
+			}
+			defer os.Remove(entryFile)
+
+			var metas []StyleMeta
+			processor := func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+				metas = append(metas, meta)
+				return strings.ReplaceAll(css, "var(--brand)", "#42b883"), nil
+			}
+
+			jsExec := newMockExecutor(t, test.mockConfig)
+			result := buildSassTest(t, entryFile, jsExec, func(options *api.BuildOptions) {
+				options.Plugins = []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithOnStyleProcessor(processor))}
+			})
+			if len(result.Errors) > 0 {
+				t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+			}
+
+			if len(metas) != 1 || metas[0].File != tmpFile || metas[0].Index != -1 || metas[0].Lang != stylesheetLang(tmpFile) {
+				t.Errorf("Unexpected style meta: %+v", metas)
+			}
+			for _, file := range result.OutputFiles {
+				if strings.HasSuffix(file.Path, ".css") && !strings.Contains(string(file.Contents), "#42b883") {
+					t.Errorf("Expected processed CSS in output, got: %s", file.Contents)
+				}
+			}
+		})
+	}
+}
+
+func TestStyleProcessorOnStylesheetsError(t *testing.T) {
+	tmpFile := createTempSassFile(t, ".a { color: red; }", "scss")
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import '%s';`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{Sass: &MockSassConfig{CSS: ".a { color: red; }"}})
+	processor := func(css string, meta StyleMeta, buildOptions *api.BuildOptions) (string, error) {
+		return "", fmt.Errorf("lint failed")
+	}
+
+	result := buildSassTest(t, entryFile, jsExec, func(options *api.BuildOptions) {
+		options.LogLevel = api.LogLevelSilent
+		options.Plugins = []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithOnStyleProcessor(processor))}
+	})
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "lint failed") {
+		t.Errorf("Expected processor error, got: %v", result.Errors)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// stylusLoader compiles .styl and .stylus files with the Stylus service of the Vue compiler.
+var stylusLoader = stylesheetLoader{
+	name:      "Stylus",
+	filter:    `\.styl(us)?$`,
+	namespace: "stylus-loader",
+	service:   "sfc.stylus.renderSync",
+}
+
+// setupStylusHandler registers handlers for Stylus files (.styl and .stylus).
+// It sets up the complete processing pipeline including path resolution and compilation.
+func setupStylusHandler(opts *Options, build *api.PluginBuild) {
+	setupStylesheetHandler(opts, build, stylusLoader)
+}
+
+// compileStylus compiles Stylus to CSS using the Vue compiler via the JS executor.
+// The style preprocessor options are passed to Stylus, allowing settings like define
+// or "include css".
+func compileStylus(filePath, source string, sourceMap bool, preprocessorOptions map[string]any, jsExecutor *jsexecutor.JsExecutor) (*stylesheetResult, error) {
+	return compileStylesheet(stylusLoader, filePath, source, sourceMap, preprocessorOptions, jsExecutor)
+}
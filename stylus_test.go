@@ -0,0 +1,215 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestCompileStylus(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockConfig  *MockEngineConfig
+		sourceMap   bool
+		expectError string
+		expectCSS   string
+		expectMap   string
+	}{
+		{
+			name: "successful_compilation",
+			mockConfig: &MockEngineConfig{ServiceResponses: map[string]interface{}{
+				"sfc.stylus.renderSync": map[string]interface{}{
+					"css":     ".button { color: #333; }",
+					"map":     `{"version":3}`,
+					"imports": []interface{}{"/test/theme.styl"},
+				},
+			}},
+			expectCSS: ".button { color: #333; }",
+		},
+		{
+			name: "with_sourcemap",
+			mockConfig: &MockEngineConfig{ServiceResponses: map[string]interface{}{
+				"sfc.stylus.renderSync": map[string]interface{}{"css": ".a {}", "map": `{"version":3}`},
+			}},
+			sourceMap: true,
+			expectCSS: ".a {}",
+			expectMap: `{"version":3}`,
+		},
+		{
+			name:        "service_error",
+			mockConfig:  &MockEngineConfig{ExecuteError: fmt.Errorf("expected \"indent\", got \"outdent\"")},
+			expectError: "stylus compilation service failed",
+		},
+		{
+			name:        "invalid_result",
+			mockConfig:  &MockEngineConfig{InvalidResult: true},
+			expectError: "invalid response from stylus compilation service",
+		},
+		{
+			name: "missing_css",
+			mockConfig: &MockEngineConfig{ServiceResponses: map[string]interface{}{
+				"sfc.stylus.renderSync": map[string]interface{}{"map": ""},
+			}},
+			expectError: "failed to extract CSS",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jsExec := newMockExecutor(t, test.mockConfig)
+			result, err := compileStylus("/test/app.styl", "primary = #333", test.sourceMap, nil, jsExec)
+
+			if test.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectError) {
+					t.Errorf("Expected error containing '%s', got: %v", test.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if result.css != test.expectCSS || result.sourceMap != test.expectMap {
+				t.Errorf("Unexpected result: %+v", result)
+			}
+		})
+	}
+}
+
+func TestCompileStylusOptions(t *testing.T) {
+	var stylusOptions map[string]interface{}
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		ServiceResponses: map[string]interface{}{
+			"sfc.stylus.renderSync": map[string]interface{}{"css": ".a {}"},
+		},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			stylusOptions = req.Args[0].(map[string]interface{})
+		},
+	})
+
+	_, err := compileStylus("/test/app.styl", "a = 1", false, map[string]any{
+		"include css": true,
+		"filename":    "/should/not/override.styl",
+	}, jsExec)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if stylusOptions["include css"] != true {
+		t.Errorf("Expected preprocessor options to be passed, got %v", stylusOptions)
+	}
+	if stylusOptions["filename"] != "/test/app.styl" || stylusOptions["data"] != "a = 1" {
+		t.Errorf("Expected file name and source to take precedence, got %v", stylusOptions)
+	}
+}
+
+// Integration tests
+
+func TestStylusCompilationWithMockEngine(t *testing.T) {
+	tmpFile := createTempSassFile(t, "@import 'theme'\n.button\n  color primary", "styl")
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import '%s';`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{ServiceResponses: map[string]interface{}{
+		"sfc.stylus.renderSync": map[string]interface{}{
+			"css":     ".button { color: #1890ff; }",
+			"imports": []interface{}{filepath.Join(filepath.Dir(tmpFile), "theme.styl")},
+		},
+	}})
+
+	result := buildSassTest(t, entryFile, jsExec, func(options *api.BuildOptions) {
+		options.Sourcemap = api.SourceMapExternal
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	foundCSS := false
+	for _, file := range result.OutputFiles {
+		if strings.HasSuffix(file.Path, ".css") {
+			foundCSS = true
+			if !strings.Contains(string(file.Contents), ".button") {
+				t.Errorf("Expected CSS to contain '.button', got: %s", file.Contents)
+			}
+		}
+	}
+	if !foundCSS {
+		t.Error("Expected CSS output file, got none")
+	}
+}
+
+func TestStylusLoadHandlerErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	entryFile := filepath.Join(tmpDir, "entry.js")
+
+	t.Run("read_error", func(t *testing.T) {
+		if err := os.WriteFile(entryFile, []byte(`import './missing.styl';`), 0644); err != nil {
+			t.Fatalf("Failed to create entry file: %v", err)
+		}
+		jsExec := newMockExecutor(t, &MockEngineConfig{})
+
+		result := buildSassTest(t, entryFile, jsExec)
+		if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "failed to read stylus file") {
+			t.Errorf("Expected read error, got: %v", result.Errors)
+		}
+	})
+
+	t.Run("compile_error", func(t *testing.T) {
+		stylusFile := filepath.Join(tmpDir, "broken.styl")
+		if err := os.WriteFile(stylusFile, []byte(".a\n  color: undefined-fn("), 0644); err != nil {
+			t.Fatalf("Failed to create stylus file: %v", err)
+		}
+		if err := os.WriteFile(entryFile, []byte(`import './broken.styl';`), 0644); err != nil {
+			t.Fatalf("Failed to create entry file: %v", err)
+		}
+		jsExec := newMockExecutor(t, &MockEngineConfig{ExecuteError: fmt.Errorf("expected \")\", got \"eos\"")})
+
+		result := buildSassTest(t, entryFile, jsExec)
+		if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "got \"eos\"") {
+			t.Errorf("Expected compile error, got: %v", result.Errors)
+		}
+	})
+
+	t.Run("resolve_error", func(t *testing.T) {
+		if err := os.WriteFile(entryFile, []byte(`import './broken.styl';`), 0644); err != nil {
+			t.Fatalf("Failed to create entry file: %v", err)
+		}
+		jsExec := newMockExecutor(t, &MockEngineConfig{})
+
+		result := buildSassTest(t, entryFile, jsExec, func(options *api.BuildOptions) {
+			options.TsconfigRaw = `{invalid json}`
+		})
+		if len(result.Errors) == 0 {
+			t.Error("Expected build errors due to invalid tsconfig, got none")
+		}
+	})
+}
+
+func TestStylusWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"theme.styl": "primary = #1890ff",
+		"app.styl":   "@import 'theme'\n.button\n  color primary",
+		"main.js":    `import './app.styl';`,
+	})
+
+	result := buildWithCompiler(t, tmpDir, "main.js", nil)
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if css := outputFile(result, ".css"); !strings.Contains(css, ".button") || !strings.Contains(css, "#1890ff") {
+		t.Errorf("Expected the compiled Stylus in the CSS, got:\n%s", css)
+	}
+}
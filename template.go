@@ -0,0 +1,85 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// TemplatePreprocessError is returned by a TemplatePreprocessor to report where an error occurred.
+// Line is 1-based and Column is 0-based, both relative to the template source passed to the preprocessor.
+// The position is mapped back to the .vue file when the error is reported.
+type TemplatePreprocessError struct {
+	Message string // Error message
+	Line    int    // 1-based line in the template source, 0 if unknown
+	Column  int    // 0-based column in the template source
+}
+
+// Error implements the error interface.
+func (e *TemplatePreprocessError) Error() string {
+	return e.Message
+}
+
+// preprocessTemplate converts a <template> written in another language to HTML using the
+// preprocessor registered for its lang attribute. The compiler requests the conversion with the
+// template content and the position of the content in the .vue file, and compiles the returned
+// HTML in place of the content. Errors are returned as build messages located in the .vue file.
+func preprocessTemplate(opts *Options, filePath, source string, request map[string]interface{}, buildOptions *api.BuildOptions) (string, *api.Message) {
+	lang, _ := request["lang"].(string)
+	content, _ := request["content"].(string)
+	src, _ := request["src"].(string)
+	contentLine, _ := toInt(request["line"])
+	contentColumn, _ := toInt(request["column"])
+
+	preprocessor, exists := opts.templatePreprocessors[lang]
+	if !exists {
+		return "", &api.Message{
+			Text: fmt.Sprintf("Template language %q is not supported, register a preprocessor with WithTemplatePreprocessor", lang),
+			Location: &api.Location{
+				File: filePath,
+				Line: contentLine,
+			},
+		}
+	}
+	if src != "" {
+		return "", &api.Message{
+			Text: fmt.Sprintf("Template language %q is not supported with the src attribute, inline the template instead", lang),
+			Location: &api.Location{
+				File: filePath,
+				Line: contentLine,
+			},
+		}
+	}
+
+	if content == "" {
+		// Empty template
+		return "", nil
+	}
+
+	html, err := preprocessor(content, filePath, buildOptions)
+	if err != nil {
+		// Map the error position from the template source back to the .vue file
+		location := &api.Location{File: filePath, Line: contentLine}
+		var preprocessErr *TemplatePreprocessError
+		if errors.As(err, &preprocessErr) && preprocessErr.Line > 0 {
+			location.Line = contentLine + preprocessErr.Line - 1
+			location.Column = preprocessErr.Column
+			if preprocessErr.Line == 1 {
+				location.Column += contentColumn
+			}
+			if lines := strings.Split(source, "\n"); location.Line <= len(lines) {
+				location.LineText = lines[location.Line-1]
+			}
+		}
+		return "", &api.Message{
+			Text:     fmt.Sprintf("Template preprocessor %q failed: %v", lang, err),
+			Location: location,
+		}
+	}
+	return html, nil
+}
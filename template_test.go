@@ -0,0 +1,198 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestPreprocessTemplate(t *testing.T) {
+	opts := newOptions()
+	WithTemplatePreprocessor("pug", func(source string, filePath string, buildOptions *api.BuildOptions) (string, error) {
+		switch strings.TrimSpace(source) {
+		case "div Hello":
+			return "<div>Hello</div>", nil
+		case "ol items":
+			return "<ol>\n<li>a</li>\n<li>b</li>\n</ol>", nil
+		case "div\n  broken(":
+			return "", &TemplatePreprocessError{Message: "unexpected end of attributes", Line: 3, Column: 9}
+		case "broken(":
+			return "", &TemplatePreprocessError{Message: "unexpected end of attributes", Line: 1, Column: 7}
+		default:
+			return "", fmt.Errorf("pug failed")
+		}
+	})(opts)
+
+	request := func(lang, content string, line, column int) map[string]interface{} {
+		return map[string]interface{}{"lang": lang, "content": content, "line": float64(line), "column": float64(column)}
+	}
+
+	t.Run("converted", func(t *testing.T) {
+		source := "<template lang=\"pug\">\ndiv Hello\n</template>"
+		html, msg := preprocessTemplate(opts, "/test/App.vue", source, request("pug", "\ndiv Hello\n", 1, 21), &api.BuildOptions{})
+		if msg != nil || html != "<div>Hello</div>" {
+			t.Errorf("Expected converted template, got %q, %+v", html, msg)
+		}
+	})
+
+	t.Run("more_lines", func(t *testing.T) {
+		// The HTML replaces the template content, it may have more lines than the source
+		source := "<script>export default {}</script>\n<template lang=\"pug\">\nol items\n</template>"
+		html, msg := preprocessTemplate(opts, "/test/App.vue", source, request("pug", "\nol items\n", 2, 21), &api.BuildOptions{})
+		if msg != nil || html != "<ol>\n<li>a</li>\n<li>b</li>\n</ol>" {
+			t.Errorf("Expected converted template, got %q, %+v", html, msg)
+		}
+	})
+
+	t.Run("empty_template", func(t *testing.T) {
+		html, msg := preprocessTemplate(opts, "/test/App.vue", "<template lang=\"pug\"></template>", request("pug", "", 1, 21), &api.BuildOptions{})
+		if msg != nil || html != "" {
+			t.Errorf("Expected empty template, got %q, %+v", html, msg)
+		}
+	})
+
+	t.Run("unregistered_language", func(t *testing.T) {
+		_, msg := preprocessTemplate(opts, "/test/App.vue", "<script></script>\n<template lang=\"slm\">p</template>", request("slm", "p", 2, 21), &api.BuildOptions{})
+		if msg == nil || !strings.Contains(msg.Text, "WithTemplatePreprocessor") || msg.Location.Line != 2 {
+			t.Errorf("Expected unsupported language error on line 2, got: %+v", msg)
+		}
+	})
+
+	t.Run("src_attribute", func(t *testing.T) {
+		req := request("pug", "div Hello", 1, 40)
+		req["src"] = "./view.pug"
+		_, msg := preprocessTemplate(opts, "/test/App.vue", `<template lang="pug" src="./view.pug"></template>`, req, &api.BuildOptions{})
+		if msg == nil || !strings.Contains(msg.Text, "src attribute") {
+			t.Errorf("Expected src attribute error, got: %+v", msg)
+		}
+	})
+
+	t.Run("located_error", func(t *testing.T) {
+		source := "<!-- header -->\n<template lang=\"pug\">\ndiv\n  broken(\n</template>"
+		_, msg := preprocessTemplate(opts, "/test/App.vue", source, request("pug", "\ndiv\n  broken(\n", 2, 20), &api.BuildOptions{})
+		if msg == nil || !strings.Contains(msg.Text, "unexpected end of attributes") {
+			t.Fatalf("Expected preprocessor error, got: %+v", msg)
+		}
+		if msg.Location.File != "/test/App.vue" || msg.Location.Line != 4 || msg.Location.Column != 9 || msg.Location.LineText != "  broken(" {
+			t.Errorf("Expected error mapped to line 4 of the .vue file, got: %+v", msg.Location)
+		}
+	})
+
+	t.Run("located_error_first_line", func(t *testing.T) {
+		source := "<template lang=\"pug\">broken(</template>"
+		_, msg := preprocessTemplate(opts, "/test/App.vue", source, request("pug", "broken(", 1, 20), &api.BuildOptions{})
+		if msg == nil || msg.Location.Line != 1 || msg.Location.Column != 27 {
+			t.Errorf("Expected error shifted by the start tag, got: %+v", msg)
+		}
+	})
+
+	t.Run("unlocated_error", func(t *testing.T) {
+		source := "\n<template lang=\"pug\">p</template>"
+		_, msg := preprocessTemplate(opts, "/test/App.vue", source, request("pug", "p", 2, 20), &api.BuildOptions{})
+		if msg == nil || !strings.Contains(msg.Text, "pug failed") || msg.Location.Line != 2 {
+			t.Errorf("Expected error located at the template block, got: %+v", msg)
+		}
+	})
+}
+
+// Integration tests
+
+func TestVueTemplatePreprocessor(t *testing.T) {
+	tmpFile := createTempVueFile(t, "<template lang=\"pug\">\ndiv.greeting Hello\n</template>")
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import App from '%s'; console.log(App);`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	var compiledSources []string
+	var templateHtml interface{}
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		TemplatePreprocess: map[string]interface{}{
+			"lang":    "pug",
+			"content": "\ndiv.greeting Hello\n",
+			"line":    float64(1),
+			"column":  float64(21),
+		},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				compiledSources = append(compiledSources, req.Args[2].(string))
+				templateHtml = req.Args[3].(map[string]interface{})["templateHtml"]
+			}
+		},
+	})
+	preprocessor := func(source string, filePath string, buildOptions *api.BuildOptions) (string, error) {
+		if filePath != tmpFile {
+			return "", fmt.Errorf("unexpected file %s", filePath)
+		}
+		if source != "\ndiv.greeting Hello\n" {
+			return "", fmt.Errorf("unexpected template source %q", source)
+		}
+		// More lines than the template source
+		return "\n<div class=\"greeting\">\nHello\n</div>\n\n", nil
+	}
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints: []string{entryFile},
+		Bundle:      true,
+		Write:       false,
+		LogLevel:    api.LogLevelError,
+		Plugins:     []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithTemplatePreprocessor("pug", preprocessor))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	// The component is compiled again with the HTML, the .vue source is left unchanged
+	if len(compiledSources) != 2 || compiledSources[1] != "<template lang=\"pug\">\ndiv.greeting Hello\n</template>" {
+		t.Errorf("Expected the unchanged source to be compiled twice, got %q", compiledSources)
+	}
+	if templateHtml != "\n<div class=\"greeting\">\nHello\n</div>\n\n" {
+		t.Errorf("Expected preprocessed HTML to be compiled, got %q", templateHtml)
+	}
+}
+
+func TestVueTemplatePreprocessorError(t *testing.T) {
+	tmpFile := createTempVueFile(t, "<template lang=\"pug\">\ndiv(\n</template>")
+	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
+	if err := os.WriteFile(entryFile, []byte(fmt.Sprintf(`import App from '%s'; console.log(App);`, filepath.Base(tmpFile))), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+	defer os.Remove(entryFile)
+
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		TemplatePreprocess: map[string]interface{}{
+			"lang":    "pug",
+			"content": "\ndiv(\n",
+			"line":    float64(1),
+			"column":  float64(21),
+		},
+	})
+	preprocessor := func(source string, filePath string, buildOptions *api.BuildOptions) (string, error) {
+		return "", &TemplatePreprocessError{Message: "unexpected end of input", Line: 2, Column: 4}
+	}
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints: []string{entryFile},
+		Bundle:      true,
+		Write:       false,
+		LogLevel:    api.LogLevelSilent,
+		Plugins:     []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithTemplatePreprocessor("pug", preprocessor))},
+	})
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "unexpected end of input") {
+		t.Fatalf("Expected preprocessor error, got: %v", result.Errors)
+	}
+	if location := result.Errors[0].Location; location == nil || location.Line != 2 || location.Column != 4 {
+		t.Errorf("Expected error mapped to line 2 of the .vue file, got: %+v", location)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TemplateCompilerOptions holds typed options of the Vue template compiler.
+// Unset fields keep the compiler defaults, see https://vuejs.org/api/application.html#app-config-compileroptions
+type TemplateCompilerOptions struct {
+	Whitespace    string    // "condense" (default) or "preserve"
+	Comments      *bool     // Keep HTML comments in production builds, they are always kept in development
+	Delimiters    [2]string // Text interpolation delimiters, defaults to "{{" and "}}"
+	HoistStatic   *bool     // Hoist static nodes and props out of the render function
+	CacheHandlers *bool     // Cache inline event handlers
+
+	// IsCustomElement reports whether a tag is a native custom element, which is not resolved as a Vue component.
+	// It is called in Go with the tag names written in the <template> block of each component. Tags the
+	// compiler only sees at runtime, e.g. of <component :is="tag">, or of templates loaded through src
+	// aren't checked, use CustomElements for them.
+	IsCustomElement func(tag string) bool
+
+	// CustomElements are patterns matching tags of native custom elements, evaluated by the compiler.
+	// Patterns are globs (e.g. "ion-*", "?-button") or regular expressions in slashes (e.g. "/^x-/"),
+	// which must use the syntax shared by Go and JavaScript, e.g. no lookarounds or backreferences.
+	CustomElements []string
+
+	// Extra holds any other JSON serializable compiler options, typed fields take precedence
+	Extra map[string]any
+}
+
+// templateTagRegex matches the start tags of elements, the first group is the tag name
+var templateTagRegex = regexp.MustCompile(`<([A-Za-z][A-Za-z0-9_.:-]*)`)
+
+// validate checks the typed options, it's called when the options are set.
+// Regular expressions of CustomElements must be valid in both Go and JavaScript.
+func (o *TemplateCompilerOptions) validate() error {
+	switch o.Whitespace {
+	case "", "condense", "preserve":
+	default:
+		return fmt.Errorf("invalid template whitespace %q, expected \"condense\" or \"preserve\"", o.Whitespace)
+	}
+	if o.Delimiters != [2]string{} && (o.Delimiters[0] == "" || o.Delimiters[1] == "") {
+		return fmt.Errorf("invalid template delimiters %q, both delimiters are required", o.Delimiters)
+	}
+	for _, pattern := range o.CustomElements {
+		source, err := customElementPattern(pattern)
+		if err != nil {
+			return err
+		}
+		if _, err := regexp.Compile(source); err != nil {
+			return fmt.Errorf("invalid custom element pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// compilerOptions returns the options passed to the template compiler, merging the typed
+// fields over Extra. The options must have been validated.
+func (o *TemplateCompilerOptions) compilerOptions() map[string]any {
+	options := make(map[string]any, len(o.Extra))
+	for key, value := range o.Extra {
+		options[key] = value
+	}
+
+	if o.Whitespace != "" {
+		options["whitespace"] = o.Whitespace
+	}
+	if o.Delimiters != [2]string{} {
+		options["delimiters"] = []string{o.Delimiters[0], o.Delimiters[1]}
+	}
+	if o.Comments != nil {
+		options["comments"] = *o.Comments
+	}
+	if o.HoistStatic != nil {
+		options["hoistStatic"] = *o.HoistStatic
+	}
+	if o.CacheHandlers != nil {
+		options["cacheHandlers"] = *o.CacheHandlers
+	}
+	return options
+}
+
+// customElementMatcher returns the custom element tags and patterns of a component, passed to the
+// compiler as isCustomElement. The Go predicate is evaluated for the start tags found in the source,
+// the compiler only looks up the tags of the template. Patterns are converted to regular expressions
+// evaluated by the compiler. Nil is returned if no custom elements are configured.
+func (o *TemplateCompilerOptions) customElementMatcher(source string) map[string]any {
+	if o.IsCustomElement == nil && len(o.CustomElements) == 0 {
+		return nil
+	}
+
+	tags := []string{}
+	if o.IsCustomElement != nil {
+		seen := make(map[string]bool)
+		for _, match := range templateTagRegex.FindAllStringSubmatch(source, -1) {
+			tag := match[1]
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			if o.IsCustomElement(tag) {
+				tags = append(tags, tag)
+			}
+		}
+		sort.Strings(tags)
+	}
+
+	patterns := make([]string, 0, len(o.CustomElements))
+	for _, pattern := range o.CustomElements {
+		source, _ := customElementPattern(pattern)
+		patterns = append(patterns, source)
+	}
+
+	return map[string]any{"tags": tags, "patterns": patterns}
+}
+
+// customElementPattern converts a glob or a regular expression in slashes to the source of a JavaScript regular expression.
+// Regular expressions are passed through as is and validated by validate.
+func customElementPattern(pattern string) (string, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return pattern[1 : len(pattern)-1], nil
+	}
+	if pattern == "" {
+		return "", fmt.Errorf("invalid custom element pattern, the pattern is empty")
+	}
+
+	var source strings.Builder
+	source.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			source.WriteString(".*")
+		case '?':
+			source.WriteString(".")
+		default:
+			source.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	source.WriteString("$")
+	return source.String(), nil
+}
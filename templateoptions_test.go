@@ -0,0 +1,255 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Unit tests
+
+func TestTemplateCompilerOptionsCompilerOptions(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name     string
+		options  TemplateCompilerOptions
+		expected map[string]any
+	}{
+		{
+			name:     "empty",
+			expected: map[string]any{},
+		},
+		{
+			name: "typed_fields",
+			options: TemplateCompilerOptions{
+				Whitespace:    "preserve",
+				Comments:      &enabled,
+				Delimiters:    [2]string{"${", "}"},
+				HoistStatic:   &disabled,
+				CacheHandlers: &enabled,
+			},
+			expected: map[string]any{
+				"whitespace":    "preserve",
+				"comments":      true,
+				"delimiters":    []string{"${", "}"},
+				"hoistStatic":   false,
+				"cacheHandlers": true,
+			},
+		},
+		{
+			name:     "precedence",
+			options:  TemplateCompilerOptions{Whitespace: "condense", Extra: map[string]any{"whitespace": "preserve", "ssrCssVars": "x"}},
+			expected: map[string]any{"whitespace": "condense", "ssrCssVars": "x"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := test.options.compilerOptions()
+			if !reflect.DeepEqual(options, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, options)
+			}
+		})
+	}
+
+	// The extra options are not modified
+	extra := map[string]any{"comments": false}
+	(&TemplateCompilerOptions{Comments: &enabled, Extra: extra}).compilerOptions()
+	if extra["comments"] != false {
+		t.Error("Expected extra options to be left unchanged")
+	}
+}
+
+func TestTemplateCompilerOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     TemplateCompilerOptions
+		expectError string
+	}{
+		{
+			name:    "valid",
+			options: TemplateCompilerOptions{Whitespace: "preserve", Delimiters: [2]string{"${", "}"}, CustomElements: []string{"ion-*", "/^x-[a-z]+$/"}},
+		},
+		{
+			name:        "invalid_whitespace",
+			options:     TemplateCompilerOptions{Whitespace: "collapse"},
+			expectError: "invalid template whitespace",
+		},
+		{
+			name:        "invalid_delimiters",
+			options:     TemplateCompilerOptions{Delimiters: [2]string{"${", ""}},
+			expectError: "both delimiters are required",
+		},
+		{
+			name:        "empty_pattern",
+			options:     TemplateCompilerOptions{CustomElements: []string{""}},
+			expectError: "the pattern is empty",
+		},
+		{
+			name:        "invalid_regexp",
+			options:     TemplateCompilerOptions{CustomElements: []string{"/^(x-/"}},
+			expectError: `invalid custom element pattern "/^(x-/"`,
+		},
+		{
+			name:        "unsupported_regexp",
+			options:     TemplateCompilerOptions{CustomElements: []string{"/^x-(?!chart)/"}},
+			expectError: "invalid custom element pattern",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.options.validate()
+			if test.expectError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectError) {
+				t.Errorf("Expected error containing '%s', got: %v", test.expectError, err)
+			}
+		})
+	}
+}
+
+func TestTemplateCompilerOptionsCustomElementMatcher(t *testing.T) {
+	source := `<template><ion-button @click="go"><Icon /></ion-button><ion-button /><my-widget></my-widget></template>
+<script setup lang="ts">const items: Array<string> = []; const html = '<ion-script-tag>'</script>
+<style>/* <ion-style-tag> */</style>`
+
+	if matcher := (&TemplateCompilerOptions{}).customElementMatcher(source); matcher != nil {
+		t.Errorf("Expected no matcher without custom elements, got %v", matcher)
+	}
+
+	options := &TemplateCompilerOptions{
+		IsCustomElement: func(tag string) bool { return strings.HasPrefix(tag, "ion-") || tag == "my-widget" },
+		CustomElements:  []string{"x-*", "?-chart", "/^(foo|bar)-/"},
+	}
+	// Tags are collected from the whole file, the compiler only looks up the tags of the template
+	matcher := options.customElementMatcher(source)
+	expected := map[string]any{
+		"tags":     []string{"ion-button", "ion-script-tag", "ion-style-tag", "my-widget"},
+		"patterns": []string{`^x-.*$`, `^.-chart$`, `^(foo|bar)-`},
+	}
+	if !reflect.DeepEqual(matcher, expected) {
+		t.Errorf("Expected %v, got %v", expected, matcher)
+	}
+
+	// HTML converted from templates in other languages is matched the same way
+	matcher = options.customElementMatcher("\n<ion-card>\n<h1>Title</h1>\n</ion-card>\n")
+	if tags := matcher["tags"].([]string); !reflect.DeepEqual(tags, []string{"ion-card"}) {
+		t.Errorf("Expected the tags of the HTML, got %v", tags)
+	}
+}
+
+func TestCustomElementPattern(t *testing.T) {
+	tests := map[string]string{
+		"ion-*":        `^ion-.*$`,
+		"a.b?":         `^a\.b.$`,
+		"/^x-[a-z]+$/": `^x-[a-z]+$`,
+		"/":            `^/$`,
+	}
+	for pattern, expected := range tests {
+		source, err := customElementPattern(pattern)
+		if err != nil || source != expected {
+			t.Errorf("Expected %s for %q, got %s, %v", expected, pattern, source, err)
+		}
+	}
+}
+
+// Integration tests
+
+func TestVueTemplateCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "App.vue"), []byte(`<template><ion-app><ion-content /></ion-app></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	var compileOptions map[string]interface{}
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				compileOptions = req.Args[3].(map[string]interface{})
+			}
+		},
+	})
+
+	build := func(options TemplateCompilerOptions) api.BuildResult {
+		return api.Build(api.BuildOptions{
+			EntryPoints:   []string{entryFile},
+			Bundle:        true,
+			Write:         false,
+			LogLevel:      api.LogLevelSilent,
+			AbsWorkingDir: tmpDir,
+			Plugins: []api.Plugin{NewPlugin(
+				WithJsExecutor(jsExec),
+				WithTemplateCompilerOptions(options),
+			)},
+		})
+	}
+
+	result := build(TemplateCompilerOptions{
+		Whitespace:      "preserve",
+		Extra:           map[string]any{"comments": true},
+		IsCustomElement: func(tag string) bool { return strings.HasPrefix(tag, "ion-") },
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	compilerOptions, _ := compileOptions["compilerOptions"].(map[string]any)
+	if compilerOptions["whitespace"] != "preserve" || compilerOptions["comments"] != true {
+		t.Errorf("Expected merged compiler options, got %v", compilerOptions)
+	}
+	matcher, _ := compileOptions["isCustomElement"].(map[string]any)
+	if !reflect.DeepEqual(matcher["tags"], []string{"ion-app", "ion-content"}) {
+		t.Errorf("Expected the ion-* tags to be custom elements, got %v", compileOptions["isCustomElement"])
+	}
+
+	// Invalid options are reported as build errors
+	result = build(TemplateCompilerOptions{Whitespace: "none"})
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "invalid template whitespace") {
+		t.Errorf("Expected invalid whitespace error, got: %v", result.Errors)
+	}
+	result = build(TemplateCompilerOptions{CustomElements: []string{"/^(x-/"}})
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "invalid custom element pattern") {
+		t.Errorf("Expected invalid pattern error, got: %v", result.Errors)
+	}
+}
+
+// TestVueTemplateCompilerWithCompiler tests that the compiler bundle renders native custom elements without resolving them
+func TestVueTemplateCompilerWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue": `<template><ion-button>Go</ion-button><x-chart /><my-card /></template>`,
+		"main.js": `import App from './App.vue'; console.log(App);`,
+	})
+
+	result := buildWithCompiler(t, tmpDir, "main.js", nil, WithTemplateCompilerOptions(TemplateCompilerOptions{
+		IsCustomElement: func(tag string) bool { return strings.HasPrefix(tag, "ion-") },
+		CustomElements:  []string{"x-*"},
+	}))
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	js := outputFile(result, ".js")
+	for _, tag := range []string{"ion-button", "x-chart"} {
+		if strings.Contains(js, `resolveComponent("`+tag+`")`) {
+			t.Errorf("Expected %s to be rendered as a custom element, got:\n%s", tag, js)
+		}
+	}
+	if !strings.Contains(js, `resolveComponent("my-card")`) {
+		t.Errorf("Expected my-card to be resolved as a component, got:\n%s", js)
+	}
+}
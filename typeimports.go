@@ -0,0 +1,343 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// scriptBlockRegex matches the <script> blocks of a component, the first group is the content
+var scriptBlockRegex = regexp.MustCompile(`(?s)<script\b[^>]*>(.*?)</script>`)
+
+// importSourceRegex matches the sources of import declarations, re-exports and import types
+var importSourceRegex = regexp.MustCompile(`(?:\bfrom\s*|\bimport\s*\(\s*|\bimport\s+)['"]([^'"\n]+)['"]`)
+
+// exportsConditions are the conditions of package exports tried after "types", in order
+var exportsConditions = []string{"import", "module", "default", "require", "node"}
+
+// tsconfigTypes holds the tsconfig options used to resolve imported types.
+// It is loaded once per build, together with the type files of the packages looked up by the build.
+type tsconfigTypes struct {
+	baseUrl         string              // Absolute baseUrl, empty if not set
+	paths           map[string][]string // Path patterns mapped to absolute targets
+	globalTypeFiles []string            // Declaration files listed in files and include
+
+	packagesMu sync.Mutex
+	packages   map[string]string // Type files of package imports by directory and specifier, empty if not found
+}
+
+// loadTsconfigTypes reads the options used to resolve imported types from the tsconfig of the build.
+// Paths are relative to baseUrl if set, otherwise to the tsconfig directory, like TypeScript.
+func loadTsconfigTypes(buildOptions *api.BuildOptions) (*tsconfigTypes, error) {
+	tsconfig, tsconfigAbsDir, err := readTsconfig(buildOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tsconfigTypes{paths: make(map[string][]string), packages: make(map[string]string)}
+	compilerOptions, _ := tsconfig["compilerOptions"].(map[string]interface{})
+	pathsBase := tsconfigAbsDir
+	if baseUrl, ok := compilerOptions["baseUrl"].(string); ok && baseUrl != "" {
+		config.baseUrl = filepath.Join(tsconfigAbsDir, baseUrl)
+		pathsBase = config.baseUrl
+	}
+	if paths, ok := compilerOptions["paths"].(map[string]interface{}); ok {
+		for pattern, targets := range paths {
+			for _, target := range toStringSlice(targets) {
+				config.paths[pattern] = append(config.paths[pattern], filepath.Join(pathsBase, target))
+			}
+		}
+	}
+
+	// Declaration files listed without globs declare global types
+	for _, key := range []string{"files", "include"} {
+		for _, file := range toStringSlice(tsconfig[key]) {
+			if strings.HasSuffix(file, ".d.ts") && !strings.ContainsAny(file, "*?") {
+				file = filepath.Join(tsconfigAbsDir, file)
+				if isFile(file) {
+					config.globalTypeFiles = append(config.globalTypeFiles, toPosixPath(file))
+				}
+			}
+		}
+	}
+	return config, nil
+}
+
+// resolveTypeImports resolves the non-relative imports of the script blocks of a component to type files,
+// through the tsconfig paths and baseUrl, then the types of packages in node_modules.
+// The compiler only resolves relative imports of types referenced by defineProps and defineEmits,
+// these imports are rewritten to the resolved files before compilation.
+// Returns the resolved imports and the global type files, or nil if there are none.
+func resolveTypeImports(source, filePath string, config *tsconfigTypes) map[string]any {
+	imports := make(map[string]string)
+	for _, block := range scriptBlockRegex.FindAllStringSubmatch(source, -1) {
+		for _, match := range importSourceRegex.FindAllStringSubmatch(block[1], -1) {
+			specifier := match[1]
+			if _, seen := imports[specifier]; seen || strings.HasPrefix(specifier, ".") {
+				continue
+			}
+			if file := config.resolve(specifier, filepath.Dir(filePath)); file != "" {
+				imports[specifier] = toPosixPath(file)
+			}
+		}
+	}
+
+	if len(imports) == 0 && len(config.globalTypeFiles) == 0 {
+		return nil
+	}
+	return map[string]any{"imports": imports, "globalTypeFiles": config.globalTypeFiles}
+}
+
+// resolve returns the type file of a non-relative import from dir, or an empty string if it can't be resolved.
+func (c *tsconfigTypes) resolve(specifier, dir string) string {
+	if filepath.IsAbs(specifier) {
+		return resolveTypeFile(specifier)
+	}
+
+	// Step 1: Try the targets of the longest matching path pattern
+	if targets, wildcard, ok := c.matchPaths(specifier); ok {
+		for _, target := range targets {
+			if file := resolveTypeFile(strings.Replace(target, "*", wildcard, 1)); file != "" {
+				return file
+			}
+		}
+	}
+
+	// Step 2: Resolve the import relative to baseUrl
+	if c.baseUrl != "" {
+		if file := resolveTypeFile(filepath.Join(c.baseUrl, specifier)); file != "" {
+			return file
+		}
+	}
+
+	// Step 3: Look up the package in node_modules, then its @types package
+	// Components of a directory share their lookups, most import the same packages
+	key := dir + "\x00" + specifier
+	c.packagesMu.Lock()
+	file, ok := c.packages[key]
+	c.packagesMu.Unlock()
+	if !ok {
+		file = resolvePackageTypes(specifier, dir)
+		c.packagesMu.Lock()
+		c.packages[key] = file
+		c.packagesMu.Unlock()
+	}
+	return file
+}
+
+// matchPaths returns the targets of the path pattern matching the specifier and the text matched by its wildcard.
+// Exact patterns take precedence, then the wildcard pattern with the longest prefix.
+func (c *tsconfigTypes) matchPaths(specifier string) ([]string, string, bool) {
+	if targets, ok := c.paths[specifier]; ok {
+		return targets, "", true
+	}
+
+	patterns := make([]string, 0, len(c.paths))
+	for pattern := range c.paths {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var matched, wildcard string
+	matchedPrefix := -1
+	for _, pattern := range patterns {
+		prefix, suffix, ok := strings.Cut(pattern, "*")
+		if !ok || len(prefix) <= matchedPrefix {
+			continue
+		}
+		if len(specifier) >= len(prefix)+len(suffix) && strings.HasPrefix(specifier, prefix) && strings.HasSuffix(specifier, suffix) {
+			matched, matchedPrefix = pattern, len(prefix)
+			wildcard = specifier[len(prefix) : len(specifier)-len(suffix)]
+		}
+	}
+	if matched == "" {
+		return nil, "", false
+	}
+	return c.paths[matched], wildcard, true
+}
+
+// resolvePackageTypes returns the type file of a package import, looking up node_modules from dir to the root.
+func resolvePackageTypes(specifier, dir string) string {
+	name, subpath := specifier, ""
+	parts := strings.SplitN(specifier, "/", 3)
+	if strings.HasPrefix(specifier, "@") && len(parts) >= 2 {
+		name = parts[0] + "/" + parts[1]
+		if len(parts) == 3 {
+			subpath = parts[2]
+		}
+	} else if len(parts) >= 2 {
+		name = parts[0]
+		subpath = strings.TrimPrefix(specifier, name+"/")
+	}
+
+	// Type packages of scoped packages are named @types/scope__name
+	typesName := "@types/" + strings.Replace(strings.TrimPrefix(name, "@"), "/", "__", 1)
+
+	for {
+		for _, pkg := range []string{name, typesName} {
+			pkgDir := filepath.Join(dir, "node_modules", filepath.FromSlash(pkg))
+			if info, err := os.Stat(pkgDir); err == nil && info.IsDir() {
+				if file := packageTypes(pkgDir, subpath); file != "" {
+					return file
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// packageTypes returns the type file of a subpath of a package, from the "types" condition of its exports,
+// then its types or typings field, or the declaration file next to the file of the subpath.
+func packageTypes(pkgDir, subpath string) string {
+	var pkg map[string]interface{}
+	if data, err := os.ReadFile(filepath.Join(pkgDir, "package.json")); err == nil {
+		json.Unmarshal(data, &pkg)
+	}
+
+	// Step 1: Package exports
+	if exports, ok := pkg["exports"]; ok {
+		entrySubpath := "."
+		if subpath != "" {
+			entrySubpath = "./" + subpath
+		}
+		if target, wildcard, ok := exportsEntry(exports, entrySubpath); ok {
+			if types := exportsTypes(target); types != "" {
+				if file := resolveTypeFile(filepath.Join(pkgDir, filepath.FromSlash(strings.Replace(types, "*", wildcard, 1)))); file != "" {
+					return file
+				}
+			}
+		}
+	}
+
+	// Step 2: Types fields of the package, or its main file
+	if subpath == "" {
+		for _, field := range []string{"types", "typings", "main"} {
+			if value, ok := pkg[field].(string); ok && value != "" {
+				if file := resolveTypeFile(filepath.Join(pkgDir, filepath.FromSlash(value))); file != "" {
+					return file
+				}
+			}
+		}
+		return resolveTypeFile(filepath.Join(pkgDir, "index"))
+	}
+	return resolveTypeFile(filepath.Join(pkgDir, filepath.FromSlash(subpath)))
+}
+
+// exportsEntry returns the exports entry of a subpath ("." or "./name") and the text matched by its wildcard.
+func exportsEntry(exports interface{}, subpath string) (interface{}, string, bool) {
+	entries, ok := exports.(map[string]interface{})
+	if !ok {
+		// A string or an array exports the main entry
+		return exports, "", subpath == "."
+	}
+
+	// Exports without subpaths are the conditions of the main entry
+	isConditions := true
+	for key := range entries {
+		if strings.HasPrefix(key, ".") {
+			isConditions = false
+			break
+		}
+	}
+	if isConditions {
+		return exports, "", subpath == "."
+	}
+
+	if entry, ok := entries[subpath]; ok {
+		return entry, "", true
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	for _, key := range keys {
+		prefix, suffix, ok := strings.Cut(key, "*")
+		if ok && len(subpath) >= len(prefix)+len(suffix) && strings.HasPrefix(subpath, prefix) && strings.HasSuffix(subpath, suffix) {
+			return entries[key], subpath[len(prefix) : len(subpath)-len(suffix)], true
+		}
+	}
+	return nil, "", false
+}
+
+// exportsTypes returns the type file of an exports target, the "types" condition takes precedence.
+// For JavaScript targets the declaration file next to it is returned.
+func exportsTypes(target interface{}) string {
+	switch target := target.(type) {
+	case string:
+		return target
+	case []interface{}:
+		for _, item := range target {
+			if types := exportsTypes(item); types != "" {
+				return types
+			}
+		}
+	case map[string]interface{}:
+		for _, condition := range append([]string{"types"}, exportsConditions...) {
+			if value, ok := target[condition]; ok {
+				if types := exportsTypes(value); types != "" {
+					return types
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// resolveTypeFile returns the type file of a path, trying the extensions and index files
+// like the compiler does for relative imports, and declaration files next to JavaScript files.
+func resolveTypeFile(path string) string {
+	base := path
+	for _, ext := range []string{".js", ".mjs", ".cjs"} {
+		if strings.HasSuffix(path, ext) {
+			base = strings.TrimSuffix(path, ext)
+			break
+		}
+	}
+	candidates := []string{
+		path,
+		base + ".ts",
+		base + ".tsx",
+		base + ".d.ts",
+		base + ".d.mts",
+		base + ".d.cts",
+		filepath.Join(path, "index.ts"),
+		filepath.Join(path, "index.tsx"),
+		filepath.Join(path, "index.d.ts"),
+	}
+	for _, candidate := range candidates {
+		if isTypeFile(candidate) && isFile(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// isTypeFile reports whether the compiler can read types from the file.
+func isTypeFile(path string) bool {
+	for _, ext := range []string{".ts", ".tsx", ".mts", ".cts", ".vue"} {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFile reports whether the path is an existing regular file.
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
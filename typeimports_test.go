@@ -0,0 +1,234 @@
+// Copyright 2025 Brian Wang <wangbuke@gmail.com>
+// SPDX-License-Identifier: Apache-2.0
+
+package vueplugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsexecutor "github.com/buke/js-executor"
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// writeTypeImportsProject writes a project with aliased type files, packages with types and global types
+func writeTypeImportsProject(t *testing.T) string {
+	tmpDir := t.TempDir()
+	for file, contents := range map[string]string{
+		"tsconfig.json":                             `{"compilerOptions":{"baseUrl":"src","paths":{"@/*":["*"],"@shared":["../shared/index.ts"]}},"include":["src/**/*","env.d.ts"]}`,
+		"env.d.ts":                                  `declare global { interface Window { title: string } }`,
+		"src/types/index.ts":                        `export interface Props { msg: string }`,
+		"src/utils.ts":                              `export type Size = 'sm' | 'lg'`,
+		"shared/index.ts":                           `export interface Shared { id: number }`,
+		"node_modules/ui-kit/package.json":          `{"exports":{".":{"types":"./dist/index.d.ts","import":"./dist/index.mjs"},"./button":{"import":"./dist/button.mjs"}}}`,
+		"node_modules/ui-kit/dist/index.d.ts":       `export interface ButtonProps { label: string }`,
+		"node_modules/ui-kit/dist/button.d.mts":     `export interface ButtonProps { label: string }`,
+		"node_modules/legacy/package.json":          `{"typings":"types/legacy.d.ts"}`,
+		"node_modules/legacy/types/legacy.d.ts":     `export interface Legacy { name: string }`,
+		"node_modules/@types/scope__pkg/index.d.ts": `export interface Scoped { name: string }`,
+		"node_modules/untyped/index.js":             `module.exports = {}`,
+	} {
+		path := filepath.Join(tmpDir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", file, err)
+		}
+	}
+	return tmpDir
+}
+
+// Unit tests
+
+func TestResolveTypeImports(t *testing.T) {
+	tmpDir := writeTypeImportsProject(t)
+	source := `<script setup lang="ts">
+import type { Props } from '@/types'
+import type { Size } from "utils"
+import type { Shared } from '@shared'
+import { type ButtonProps } from 'ui-kit'
+import type { ButtonProps as Button } from 'ui-kit/button'
+import type { Legacy } from 'legacy'
+import type { Scoped } from '@scope/pkg'
+import untyped from 'untyped'
+import { ref } from './ref'
+defineProps<Props & { size: Size; shared: Shared; button: ButtonProps; legacy: Legacy; scoped: Scoped }>()
+</script>
+<template><div>from '@/types'</div></template>`
+
+	config, err := loadTsconfigTypes(&api.BuildOptions{Tsconfig: filepath.Join(tmpDir, "tsconfig.json")})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	result := resolveTypeImports(source, filepath.Join(tmpDir, "src", "App.vue"), config)
+	file := func(path string) string { return toPosixPath(filepath.Join(tmpDir, filepath.FromSlash(path))) }
+	expected := map[string]string{
+		"@/types":       file("src/types/index.ts"),
+		"utils":         file("src/utils.ts"),
+		"@shared":       file("shared/index.ts"),
+		"ui-kit":        file("node_modules/ui-kit/dist/index.d.ts"),
+		"ui-kit/button": file("node_modules/ui-kit/dist/button.d.mts"),
+		"legacy":        file("node_modules/legacy/types/legacy.d.ts"),
+		"@scope/pkg":    file("node_modules/@types/scope__pkg/index.d.ts"),
+	}
+	if !reflect.DeepEqual(result["imports"], expected) {
+		t.Errorf("Expected imports %v, got %v", expected, result["imports"])
+	}
+	if !reflect.DeepEqual(result["globalTypeFiles"], []string{file("env.d.ts")}) {
+		t.Errorf("Expected global type files, got %v", result["globalTypeFiles"])
+	}
+
+	// Package lookups are shared by the components of a build
+	if err := os.RemoveAll(filepath.Join(tmpDir, "node_modules", "legacy")); err != nil {
+		t.Fatalf("Failed to remove package: %v", err)
+	}
+	result = resolveTypeImports(source, filepath.Join(tmpDir, "src", "Other.vue"), config)
+	if imports, _ := result["imports"].(map[string]string); imports["legacy"] != expected["legacy"] {
+		t.Errorf("Expected the package lookup to be reused, got %v", result["imports"])
+	}
+
+	// Components without resolvable imports and builds without tsconfig need no type resolution
+	config, err = loadTsconfigTypes(&api.BuildOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result := resolveTypeImports(`<script setup>import { ref } from 'vue'</script>`, filepath.Join(tmpDir, "App.vue"), config); result != nil {
+		t.Errorf("Expected no type resolution, got %v", result)
+	}
+
+	if _, err := loadTsconfigTypes(&api.BuildOptions{TsconfigRaw: `{invalid json}`}); err == nil {
+		t.Error("Expected error for invalid tsconfig")
+	}
+}
+
+func TestMatchPaths(t *testing.T) {
+	config := &tsconfigTypes{paths: map[string][]string{
+		"@/*":            {"/src/*"},
+		"@/components/*": {"/components/*", "/fallback/*"},
+		"#config":        {"/config.ts"},
+		"*.vue":          {"/vue/*.vue"},
+	}}
+	tests := []struct {
+		specifier string
+		targets   []string
+		wildcard  string
+		ok        bool
+	}{
+		{"@/types", []string{"/src/*"}, "types", true},
+		{"@/components/Button", []string{"/components/*", "/fallback/*"}, "Button", true},
+		{"#config", []string{"/config.ts"}, "", true},
+		{"App.vue", []string{"/vue/*.vue"}, "App", true},
+		{"vue", nil, "", false},
+	}
+	for _, test := range tests {
+		targets, wildcard, ok := config.matchPaths(test.specifier)
+		if !reflect.DeepEqual(targets, test.targets) || wildcard != test.wildcard || ok != test.ok {
+			t.Errorf("matchPaths(%q) = %v, %q, %t", test.specifier, targets, wildcard, ok)
+		}
+	}
+}
+
+func TestExportsTypes(t *testing.T) {
+	exports := map[string]interface{}{
+		".":         map[string]interface{}{"import": map[string]interface{}{"types": "./index.d.mts", "default": "./index.mjs"}},
+		"./utils/*": []interface{}{map[string]interface{}{"default": "./dist/utils/*.js"}},
+	}
+	tests := []struct {
+		subpath  string
+		types    string
+		wildcard string
+		ok       bool
+	}{
+		{".", "./index.d.mts", "", true},
+		{"./utils/format", "./dist/utils/*.js", "format", true},
+		{"./missing", "", "", false},
+	}
+	for _, test := range tests {
+		target, wildcard, ok := exportsEntry(exports, test.subpath)
+		if exportsTypes(target) != test.types || wildcard != test.wildcard || ok != test.ok {
+			t.Errorf("exports of %q = %v, %q, %t", test.subpath, target, wildcard, ok)
+		}
+	}
+
+	// Exports without subpaths are the conditions of the main entry
+	if target, _, ok := exportsEntry(map[string]interface{}{"types": "./index.d.ts"}, "."); !ok || exportsTypes(target) != "./index.d.ts" {
+		t.Errorf("Expected the main entry conditions, got %v", target)
+	}
+	if _, _, ok := exportsEntry("./index.js", "./sub"); ok {
+		t.Error("Expected no subpath for string exports")
+	}
+}
+
+// Integration tests
+
+func TestVueTypeResolution(t *testing.T) {
+	tmpDir := writeTypeImportsProject(t)
+	if err := os.WriteFile(filepath.Join(tmpDir, "src", "App.vue"), []byte(`<script setup lang="ts">
+import type { Props } from '@/types'
+defineProps<Props>()
+</script>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "src", "main.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	var compileOptions map[string]interface{}
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				compileOptions = req.Args[3].(map[string]interface{})
+			}
+		},
+	})
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		Tsconfig:      filepath.Join(tmpDir, "tsconfig.json"),
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	typeResolution, _ := compileOptions["typeResolution"].(map[string]any)
+	imports, _ := typeResolution["imports"].(map[string]string)
+	if imports["@/types"] != toPosixPath(filepath.Join(tmpDir, "src", "types", "index.ts")) {
+		t.Errorf("Expected the aliased type import to be resolved, got %v", compileOptions["typeResolution"])
+	}
+}
+
+// TestVueTypeResolutionWithCompiler tests that the compiler bundle reads props from aliased type files
+func TestVueTypeResolutionWithCompiler(t *testing.T) {
+	tmpDir := writeTypeImportsProject(t)
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"src/App.vue": `<script setup lang="ts">
+import type { Props } from '@/types'
+defineProps<Props>()
+</script>
+<template><div>import { msg } from '@/types'</div></template>`,
+		"src/main.js": `import App from './App.vue'; console.log(App);`,
+	})
+
+	result := buildWithCompiler(t, tmpDir, "src/main.js", func(buildOptions *api.BuildOptions) {
+		buildOptions.Tsconfig = filepath.Join(tmpDir, "tsconfig.json")
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	js := outputFile(result, ".js")
+	if !strings.Contains(js, "msg: { type: String, required: true }") {
+		t.Errorf("Expected the msg prop from the aliased type, got:\n%s", js)
+	}
+	if !strings.Contains(js, "import { msg } from '@/types'") {
+		t.Errorf("Expected the template text to be unchanged, got:\n%s", js)
+	}
+}
@@ -5,7 +5,6 @@ package vueplugin
 
 import (
 	"bytes"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -28,8 +27,15 @@ func toPosixPath(path string) string {
 }
 
 // setupVueHandler registers all handlers for Vue Single File Components (.vue files).
-// It sets up the complete processing pipeline including main entry, resolve, script, template, and style handlers.
+// It sets up the complete processing pipeline including main entry, resolve, script, template, style
+// and custom block handlers.
 func setupVueHandler(opts *Options, build *api.PluginBuild) {
+	// Scope IDs are checked for collisions within each build
+	build.OnStart(func() (api.OnStartResult, error) {
+		opts.scopeIds.reset()
+		return api.OnStartResult{}, nil
+	})
+
 	// Register main entry handler for .vue files
 	registerMainEntryHandler(opts, build)
 
@@ -39,7 +45,9 @@ func setupVueHandler(opts *Options, build *api.PluginBuild) {
 	// Register handlers for Vue SFC parts
 	registerScriptHandler(build)
 	registerTemplateHandler(build)
-	registerStyleHandler(build)
+	registerAssetResolveHandler(opts, build)
+	registerStyleHandler(opts, build)
+	registerCustomBlockHandler(opts, build)
 }
 
 // registerMainEntryHandler processes .vue files and precompiles all SFC parts.
@@ -64,6 +72,29 @@ func registerMainEntryHandler(opts *Options, build *api.PluginBuild) {
 		}
 	}
 
+	// Components are compiled for development when they can be hot updated
+	if opts.hmrEnabled() {
+		isProd = false
+	}
+
+	// Templates of <script setup> components are compiled into the setup function in production,
+	// HMR rerenders need the separate template module
+	inlineTemplate := isProd
+	if opts.inlineTemplate != nil {
+		inlineTemplate = *opts.inlineTemplate
+	}
+	if opts.hmrEnabled() {
+		inlineTemplate = false
+	}
+
+	// The tsconfig options resolving imported types are read once per build
+	var types *tsconfigTypes
+	var typesErr error
+	build.OnStart(func() (api.OnStartResult, error) {
+		types, typesErr = loadTsconfigTypes(build.InitialOptions)
+		return api.OnStartResult{}, nil
+	})
+
 	build.OnLoad(api.OnLoadOptions{Filter: `\.vue$`}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
 		// Step 1: Read and preprocess the Vue source file
 		source, err := readVueSource(args, opts, build)
@@ -72,51 +103,112 @@ func registerMainEntryHandler(opts *Options, build *api.PluginBuild) {
 			return api.OnLoadResult{}, err
 		}
 
-		// Step 2: Generate unique component ID based on source content
-		hashId := generateHashId(source)
-		dataId := "data-v-" + hashId
-
-		// Step 3: Compile SFC using the JavaScript executor
-		jsResponse, err := opts.jsExecutor.Execute(&jsexecutor.JsRequest{
-			Id:      xid.New().String(),
-			Service: "sfc.vue.compileSFC",
-			Args: []interface{}{
-				hashId,
-				toPosixPath(args.Path),
-				source,
-				map[string]interface{}{
-					"sourceMap":         build.InitialOptions.Sourcemap > 0,
-					"isProd":            isProd,
-					"isSSR":             isSSR,
-					"preprocessOptions": opts.stylePreprocessorOptions,
-					"compilerOptions":   opts.templateCompilerOptions,
-				},
-			},
-		})
+		// Step 2: Generate the component scope ID with the configured strategy
+		hashId, err := generateScopeId(opts, args.Path, source, build.InitialOptions)
 		if err != nil {
-			opts.logger.Error("Failed to compile Vue SFC", "error", err, "file", args.Path)
+			opts.logger.Error("Failed to generate scope ID", "error", err, "file", args.Path)
 			return api.OnLoadResult{
 				Errors: []api.Message{{
-					Text: fmt.Sprintf("Vue SFC compilation failed: %v", err),
+					Text: err.Error(),
 					Location: &api.Location{
 						File: args.Path,
 					},
 				}},
-			}, err
+			}, nil
 		}
+		dataId := "data-v-" + hashId
 
-		// Validate compilation result format
-		compileResult, ok := jsResponse.Result.(map[string]interface{})
-		if !ok {
-			opts.logger.Error("Invalid Vue SFC compilation result", "result", jsResponse.Result, "file", args.Path)
+		// Components sharing a scope ID would share their scoped styles
+		var scopeIdWarnings []api.Message
+		if existing, ok := opts.scopeIds.register(hashId, args.Path); !ok {
+			scopeIdWarnings = append(scopeIdWarnings, api.Message{
+				Text: fmt.Sprintf("Scope ID %q is also used by %s, scoped styles of both components will affect each other", hashId, existing),
+				Location: &api.Location{
+					File: args.Path,
+				},
+			})
+		}
+
+		// TypeScript path aliases of the build, so block src attributes like "@/styles/button.scss" resolve
+		pathAlias, err := opts.pathAlias.get()
+		if err != nil {
+			opts.logger.Error("Failed to parse tsconfig path aliases", "error", err)
+			return api.OnLoadResult{}, err
+		}
+
+		// Resolve the imports of types used by defineProps and defineEmits through the tsconfig and node_modules
+		if typesErr != nil {
+			opts.logger.Error("Failed to resolve imported types", "error", typesErr, "file", args.Path)
+			return api.OnLoadResult{}, typesErr
+		}
+		typeResolution := resolveTypeImports(source, args.Path, types)
+
+		// Custom elements get their styles inlined instead of emitted as global CSS
+		isCustomElement := opts.customElementPattern != nil && opts.customElementPattern.MatchString(args.Path)
+
+		// Convert the typed template compiler options, native custom element tags are matched per component
+		compilerOptions := opts.templateCompilerOptions
+		var customElementMatcher map[string]any
+		if opts.templateCompiler != nil {
+			compilerOptions = opts.templateCompiler.compilerOptions()
+			customElementMatcher = opts.templateCompiler.customElementMatcher(source)
+		}
+
+		// Step 3: Compile SFC using the JavaScript executor, unless the result is cached
+		compileOptions := map[string]interface{}{
+			"sourceMap":          build.InitialOptions.Sourcemap > 0,
+			"isProd":             isProd,
+			"isSSR":              isSSR,
+			"customElement":      isCustomElement,
+			"preprocessOptions":  opts.stylePreprocessorOptions,
+			"compilerOptions":    compilerOptions,
+			"isCustomElement":    customElementMatcher,
+			"transformAssetUrls": opts.assetUrls.transformAssetUrls(),
+			"modulesOptions":     opts.cssModulesOptions,
+			"pathAlias":          pathAlias,
+			"typeResolution":     typeResolution,
+			"inlineTemplate":     inlineTemplate,
+		}
+		compileResult, errResult, err := compileVueSource(opts, args.Path, hashId, source, compileOptions)
+		if errResult != nil {
+			return *errResult, err
+		}
+
+		// Templates written in other languages (e.g. Pug) are converted to HTML by the registered
+		// preprocessor, then the component is compiled again with the HTML
+		if request, ok := compileResult["templatePreprocess"].(map[string]interface{}); ok {
+			html, preprocessErr := preprocessTemplate(opts, args.Path, source, request, build.InitialOptions)
+			if preprocessErr != nil {
+				opts.logger.Error("Failed to preprocess Vue template", "error", preprocessErr.Text, "file", args.Path)
+				return api.OnLoadResult{
+					Errors:     []api.Message{*preprocessErr},
+					WatchFiles: toStringSlice(compileResult["dependencies"]),
+				}, nil
+			}
+			compileOptions["templateHtml"] = html
+			if opts.templateCompiler != nil {
+				compileOptions["isCustomElement"] = opts.templateCompiler.customElementMatcher(html)
+			}
+			compileResult, errResult, err = compileVueSource(opts, args.Path, hashId, source, compileOptions)
+			if errResult != nil {
+				return *errResult, err
+			}
+		}
+
+		// External block sources (src attributes) and files loaded by style preprocessors are watched
+		// so that editing them triggers a rebuild
+		watchFiles := toStringSlice(compileResult["dependencies"])
+
+		// Compiler diagnostics are already located in the .vue file, report each one separately
+		compileErrors := convertDiagnostics(compileResult["errors"], args.Path)
+		compileWarnings := convertDiagnostics(compileResult["warnings"], args.Path)
+		if len(compileErrors) > 0 {
+			opts.logger.Error("Vue SFC compilation reported errors", "count", len(compileErrors), "file", args.Path)
 			return api.OnLoadResult{
-				Errors: []api.Message{{
-					Text: fmt.Sprintf("Invalid Vue SFC compilation result: %v", jsResponse.Result),
-					Location: &api.Location{
-						File: args.Path,
-					},
-				}},
-			}, err
+				Errors:     compileErrors,
+				Warnings:   append(scopeIdWarnings, compileWarnings...),
+				WatchFiles: watchFiles,
+			}, nil
 		}
 
 		// Step 4: Extract each SFC part from the compilation result
@@ -141,8 +233,47 @@ func registerMainEntryHandler(opts *Options, build *api.PluginBuild) {
 			}
 		}
 
+		// Custom blocks are only imported if a processor is registered for their tag
+		customBlocks, processedBlocks, customBlockWarnings := collectCustomBlocks(opts, args.Path, compileResult["customBlocks"])
+
+		// Record the compiled parts, changes are classified into HMR updates after rebuilds
+		hmrId := ""
+		if opts.hmrEnabled() {
+			hmrId = hashId
+		}
+		if opts.hmr != nil {
+			opts.hmrTracker.record(args.Path, newHmrSnapshot(hashId, script, template, styles, customBlocks))
+		}
+
+		// Server builds register the components they render, the client files are looked up in the SSR manifest
+		ssrModuleId := ""
+		if isSSR {
+			if ssrModuleId, err = moduleId(args.Path, build.InitialOptions); err != nil {
+				opts.logger.Error("Failed to determine SSR module ID", "error", err, "file", args.Path)
+				return api.OnLoadResult{
+					Errors: []api.Message{{
+						Text: err.Error(),
+						Location: &api.Location{
+							File: args.Path,
+						},
+					}},
+				}, err
+			}
+		}
+
 		// Step 5: Generate entry JavaScript code that imports and combines all SFC parts
-		contents, err := generateEntryContents(args.Path, dataId, isSSR, script, template, styles)
+		contents, origins, err := generateEntryContents(&entryOptions{
+			filePath:        args.Path,
+			dataId:          dataId,
+			hmrId:           hmrId,
+			ssrModuleId:     ssrModuleId,
+			isSSR:           isSSR,
+			isCustomElement: isCustomElement,
+			script:          script,
+			template:        template,
+			styles:          styles,
+			customBlocks:    processedBlocks,
+		})
 		if err != nil {
 			opts.logger.Error("Failed to generate Vue entry contents", "error", err, "file", args.Path)
 			return api.OnLoadResult{
@@ -155,6 +286,22 @@ func registerMainEntryHandler(opts *Options, build *api.PluginBuild) {
 			}, err
 		}
 
+		// HMR rerender updates only carry the new render function
+		if opts.hmrUpdates[args.Path] == HmrRerender {
+			contents = fmt.Sprintf("export { render } from %q;\n", toPosixPath(args.Path)+"?type=template")
+			origins = map[int]int{0: blockLine(template)}
+		}
+
+		// Map the generated entry module back to the SFC blocks it wires together
+		if build.InitialOptions.Sourcemap > 0 {
+			entryMap := buildEntrySourceMap(args.Path, source, contents, origins)
+			contents, err = appendInlineSourceMap(contents, entryMap)
+			if err != nil {
+				opts.logger.Error("Failed to generate Vue entry sourcemap", "error", err, "file", args.Path)
+				return api.OnLoadResult{}, err
+			}
+		}
+
 		// Step 6: Prepare plugin data for subsequent handlers
 		pluginData := map[string]interface{}{
 			"id":     dataId,
@@ -169,21 +316,21 @@ func registerMainEntryHandler(opts *Options, build *api.PluginBuild) {
 			pluginData["styles"] = styles
 		}
 
-		// Step 7: Extract script warnings and convert them to build warnings
-		buildWarnings := make([]api.Message, 0)
-		if warnings, ok := script["warnings"].([]interface{}); ok {
-			for _, w := range warnings {
-				if warn, isString := w.(string); isString {
-					buildWarnings = append(buildWarnings, api.Message{Text: warn})
-				}
-			}
+		if customBlocks != nil {
+			pluginData["customBlocks"] = customBlocks
 		}
 
+		// Step 7: Collect compiler warnings
+		buildWarnings := append(make([]api.Message, 0), scopeIdWarnings...)
+		buildWarnings = append(buildWarnings, compileWarnings...)
+		buildWarnings = append(buildWarnings, customBlockWarnings...)
+
 		return api.OnLoadResult{
 			Contents:   &contents,
 			ResolveDir: filepath.Dir(args.Path),
 			PluginData: pluginData,
 			Warnings:   buildWarnings,
+			WatchFiles: watchFiles,
 		}, nil
 	})
 }
@@ -215,42 +362,191 @@ func readVueSource(args api.OnLoadArgs, opts *Options, build *api.PluginBuild) (
 	return source, nil
 }
 
+// sfcDependencies returns the files loaded by an SFC compilation, external block sources
+// and files loaded by style preprocessors, false if the result doesn't list them.
+// Failed compilations are never cached, the missing file causing the error (e.g. of a <style src>)
+// isn't among the loaded files, so creating it wouldn't invalidate the cached error.
+func sfcDependencies(result interface{}) ([]string, bool) {
+	compileResult, _ := result.(map[string]interface{})
+	if errors, _ := compileResult["errors"].([]interface{}); len(errors) > 0 {
+		return nil, false
+	}
+	files, ok := compileResult["dependencies"]
+	return toStringSlice(files), ok
+}
+
+// compileVueSource compiles a .vue file with the compileSFC service of the JS executor, unless the
+// result is cached. A failed compile is returned as the load result reporting it.
+func compileVueSource(opts *Options, filePath, hashId, source string, compileOptions map[string]interface{}) (map[string]interface{}, *api.OnLoadResult, error) {
+	result, err := executeCached(opts.compileCache, opts.jsExecutor, &jsexecutor.JsRequest{
+		Id:      xid.New().String(),
+		Service: "sfc.vue.compileSFC",
+		Args: []interface{}{
+			hashId,
+			toPosixPath(filePath),
+			source,
+			compileOptions,
+		},
+	}, sfcDependencies)
+	if err != nil {
+		opts.logger.Error("Failed to compile Vue SFC", "error", err, "file", filePath)
+		return nil, &api.OnLoadResult{
+			Errors: []api.Message{{
+				Text: fmt.Sprintf("Vue SFC compilation failed: %v", err),
+				Location: &api.Location{
+					File: filePath,
+				},
+			}},
+		}, err
+	}
+
+	// Validate compilation result format
+	compileResult, ok := result.(map[string]interface{})
+	if !ok {
+		opts.logger.Error("Invalid Vue SFC compilation result", "result", result, "file", filePath)
+		return nil, &api.OnLoadResult{
+			Errors: []api.Message{{
+				Text: fmt.Sprintf("Invalid Vue SFC compilation result: %v", result),
+				Location: &api.Location{
+					File: filePath,
+				},
+			}},
+		}, nil
+	}
+	return compileResult, nil, nil
+}
+
+// toStringSlice converts a list decoded from the JS executor into a string slice.
+// Entries that are not strings are skipped.
+func toStringSlice(v interface{}) []string {
+	if items, ok := v.([]string); ok {
+		return items
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
 // generateHashId generates a unique hash ID for the given source string.
 // Uses xxhash for fast and consistent hashing across builds.
 func generateHashId(source string) string {
 	return strconv.FormatUint(xxhash.Sum64String(source), 16)
 }
 
+// cssModule holds the name and the JSON encoded class mapping of a <style module> block.
+type cssModule struct {
+	Name    string // Injection name, "$style" or the value of the module attribute
+	Classes string // JSON object mapping local class names to hashed class names
+}
+
+// collectCssModules extracts the CSS Modules class mappings from the compiled styles.
+// Styles without a module attribute are skipped. Later modules with the same name
+// override earlier ones, matching the behavior of Vue's own tooling.
+func collectCssModules(sfcStyles []map[string]interface{}) ([]cssModule, error) {
+	var modules []cssModule
+	for _, style := range sfcStyles {
+		name, ok := style["module"].(string)
+		if !ok || name == "" {
+			continue
+		}
+
+		// A failed compilation leaves no mapping, inject an empty object to keep $style defined
+		classes := style["modules"]
+		if classes == nil {
+			classes = map[string]interface{}{}
+		}
+		classesJSON, err := json.Marshal(classes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode CSS modules of %q: %w", name, err)
+		}
+		modules = append(modules, cssModule{Name: name, Classes: string(classesJSON)})
+	}
+	return modules, nil
+}
+
+// entryOptions holds the compiled parts of a component and the options of its entry module.
+type entryOptions struct {
+	filePath        string                   // Path of the .vue file
+	dataId          string                   // Scope ID of the scoped styles
+	hmrId           string                   // ID registered with the Vue HMR runtime, empty without HMR
+	ssrModuleId     string                   // ID added to ssrContext.modules, empty if not registered
+	isSSR           bool                     // Import the ssrRender function of the template
+	isCustomElement bool                     // Inline the styles for defineCustomElement
+	script          map[string]interface{}   // Compiled script block, nil if the component has none
+	template        map[string]interface{}   // Compiled template, nil if the component has none
+	styles          []map[string]interface{} // Compiled style blocks
+	customBlocks    []map[string]interface{} // Processed custom blocks
+}
+
 // generateEntryContents generates the entry JavaScript code for a Vue Single File Component.
 // It creates import statements and component setup code that combines the script, template, and styles.
 // The generated code follows Vue 3's component structure and handles SSR/CSR rendering modes.
-func generateEntryContents(filePath, dataId string, isSSR bool,
-	sfcScript map[string]interface{}, sfcTemplate map[string]interface{},
-	sfcStyles []map[string]interface{}) (string, error) {
+// Class mappings of <style module> blocks are injected through the component's __cssModules.
+// Processed custom blocks are imported and, if they export a function, called with the component.
+// With a non-empty hmrId, the component is registered with the Vue HMR runtime and exposed,
+// together with vue, to the HMR updates pushed to the browser.
+// Custom elements import their styles as strings into the component's styles array, as
+// expected by defineCustomElement, instead of emitting them as global CSS.
+// With a non-empty ssrModuleId, the server rendered component adds it to ssrContext.modules
+// when it is set up, so that the client files of the page can be found in the SSR manifest.
+// The .vue lines of the blocks the generated lines deal with are returned by 0-based generated line,
+// they are recorded while the code is generated and map the entry module back to the SFC blocks.
+func generateEntryContents(entry *entryOptions) (string, map[int]int, error) {
+
+	// Collect CSS Modules class mappings for injection into the component
+	cssModules, err := collectCssModules(entry.styles)
+	if err != nil {
+		return "", nil, err
+	}
 
 	// Convert file path to relative POSIX path for consistent import statements
-	relPath, err := filepath.Rel(".", filePath)
+	relPath, err := filepath.Rel(".", entry.filePath)
 	if err != nil {
-		relPath = filePath
+		relPath = entry.filePath
 	}
 	relPath = toPosixPath(relPath)
 
+	// The template writes to the buffer as it executes, so the generated line of a block
+	// is the number of lines written when its origin is recorded
+	contentsBuf := new(bytes.Buffer)
+	origins := make(map[int]int)
+
 	// Create template with helper functions for conditional code generation
-	tpl := template.Must(template.New(filePath).Funcs(template.FuncMap{
+	tpl := template.Must(template.New(entry.filePath).Funcs(template.FuncMap{
+		"origin": func(block string, index ...int) string {
+			line := bytes.Count(contentsBuf.Bytes(), []byte("\n"))
+			switch {
+			case block == "script":
+				origins[line] = blockLine(entry.script)
+			case block == "template":
+				origins[line] = blockLine(entry.template)
+			case block == "style" && len(index) == 1 && index[0] < len(entry.styles):
+				origins[line] = blockLine(entry.styles[index[0]])
+			}
+			return ""
+		},
 		"SSR": func() bool {
-			return isSSR
+			return entry.isSSR
 		},
 		"dataId": func() string {
-			return dataId
+			return entry.dataId
 		},
 		"hasScript": func() bool {
-			return sfcScript != nil && sfcScript["content"] != nil
+			return entry.script != nil && entry.script["content"] != nil
 		},
 		"hasTemplate": func() bool {
-			return sfcTemplate != nil && sfcTemplate["code"] != nil
+			return entry.template != nil && entry.template["code"] != nil
 		},
 		"someScoped": func() bool {
-			for _, style := range sfcStyles {
+			for _, style := range entry.styles {
 				if scoped := style["scoped"].(bool); scoped {
 					return true
 				}
@@ -259,18 +555,32 @@ func generateEntryContents(filePath, dataId string, isSSR bool,
 		},
 	}).Parse(`
 {{ if hasScript }}
-import script from '{{ .relPath }}?type=script'
+{{ origin "script" }}import script from '{{ .relPath }}?type=script'
 {{ else }}
 const script = {};
 {{ end }}
 
+{{ if .customElement }}
 {{ range $index, $_ := .styles }}
-import '{{ $.relPath }}?type=style&index={{ $index }}'
+{{ origin "style" $index }}import style{{ $index }} from '{{ $.relPath }}?type=style&index={{ $index }}&inline'
+{{ end }}
+script.styles = [{{ range $index, $_ := .styles }}{{ if $index }}, {{ end }}style{{ $index }}{{ end }}];
+{{ else }}
+{{ range $index, $_ := .styles }}
+{{ origin "style" $index }}import '{{ $.relPath }}?type=style&index={{ $index }}'
+{{ end }}
+{{ end }}
+
+{{ if .cssModules }}
+const cssModules = script.__cssModules = {};
+{{ range .cssModules }}
+cssModules[{{ .Name | printf "%q" }}] = {{ .Classes }};
+{{ end }}
 {{ end }}
 
 {{ if hasTemplate }}
-import { {{ if SSR }}ssrRender{{ else }}render{{ end }} } from '{{ .relPath }}?type=template'
-script.{{ if SSR }}ssrRender{{ else }}render{{ end }} = {{ if SSR }}ssrRender{{ else }}render{{ end }};
+{{ origin "template" }}import { {{ if SSR }}ssrRender{{ else }}render{{ end }} } from '{{ .relPath }}?type=template'
+{{ origin "template" }}script.{{ if SSR }}ssrRender{{ else }}render{{ end }} = {{ if SSR }}ssrRender{{ else }}render{{ end }};
 {{ end }}
 
 script.__file = {{ .relPath | printf "%q" }};
@@ -280,25 +590,52 @@ script.__scopeId = {{ printf "%q" (dataId) }};{{ end }}
 script.__ssrInlineRender = true;
 {{ end }}
 
+{{ if .ssrModuleId }}
+import { useSSRContext as __useSSRContext } from 'vue';
+const __setup = script.setup;
+script.setup = (props, ctx) => {
+  const ssrContext = __useSSRContext();
+  if (ssrContext) (ssrContext.modules || (ssrContext.modules = new Set())).add({{ .ssrModuleId | printf "%q" }});
+  return __setup ? __setup(props, ctx) : undefined;
+};
+{{ end }}
+
+{{ range .customBlocks }}
+import block{{ .index }} from '{{ $.relPath }}?type=custom&index={{ .index }}'
+if (typeof block{{ .index }} === 'function') block{{ .index }}(script);
+{{ end }}
+
+{{ if .hmrId }}
+import * as __vue from 'vue';
+globalThis.__VUE_HMR_VUE__ = __vue;
+script.__hmrId = {{ .hmrId | printf "%q" }};
+(globalThis.__VUE_HMR_COMPONENTS__ || (globalThis.__VUE_HMR_COMPONENTS__ = {}))[script.__hmrId] = script;
+if (typeof __VUE_HMR_RUNTIME__ !== 'undefined') __VUE_HMR_RUNTIME__.createRecord(script.__hmrId, script);
+{{ end }}
+
 {{ if hasScript }}
-export * from '{{ .relPath }}?type=script'
+{{ origin "script" }}export * from '{{ .relPath }}?type=script'
 {{ end }}
 export default script;
 `))
 
 	// Prepare template data
 	data := map[string]interface{}{
-		"relPath": relPath,
-		"styles":  sfcStyles,
+		"relPath":       relPath,
+		"styles":        entry.styles,
+		"cssModules":    cssModules,
+		"customBlocks":  entry.customBlocks,
+		"hmrId":         entry.hmrId,
+		"ssrModuleId":   entry.ssrModuleId,
+		"customElement": entry.isCustomElement,
 	}
 
 	// Execute template and generate final code
-	contentsBuf := new(bytes.Buffer)
 	if err := tpl.Execute(contentsBuf, data); err != nil {
-		return "", fmt.Errorf("failed to execute Vue entry template: %w", err)
+		return "", nil, fmt.Errorf("failed to execute Vue entry template: %w", err)
 	}
 
-	return contentsBuf.String(), nil
+	return contentsBuf.String(), origins, nil
 }
 
 // registerResolveHandler registers the file resolution handler for .vue files.
@@ -307,11 +644,11 @@ export default script;
 func registerResolveHandler(opts *Options, build *api.PluginBuild) {
 	build.OnResolve(api.OnResolveOptions{Filter: `\.vue(\?.*)?$`}, func(args api.OnResolveArgs) (api.OnResolveResult, error) {
 		// Apply TypeScript path aliases if configured
-		pathAlias, err := parseTsconfigPathAlias(build.InitialOptions)
+		path, err := opts.pathAlias.apply(args.Path)
 		if err != nil {
 			return api.OnResolveResult{}, err
 		}
-		args.Path = applyPathAlias(pathAlias, args.Path)
+		args.Path = path
 
 		// Convert relative paths to absolute paths
 		if !filepath.IsAbs(args.Path) {
@@ -371,12 +708,11 @@ func registerScriptHandler(build *api.PluginBuild) {
 
 		// Append sourcemap as inline data URL if sourcemaps are enabled and available
 		if build.InitialOptions.Sourcemap > 0 && script["map"] != nil {
-			sourceMapJSON, err := json.Marshal(script["map"])
+			var err error
+			content, err = appendInlineSourceMap(content, script["map"])
 			if err != nil {
 				return api.OnLoadResult{}, err
 			}
-			sourceMapBase64 := base64.StdEncoding.EncodeToString(sourceMapJSON)
-			content += "\n\n//@ sourceMappingURL=data:application/json;charset=utf-8;base64," + sourceMapBase64
 		}
 
 		// Determine appropriate loader based on script language
@@ -395,33 +731,37 @@ func registerScriptHandler(build *api.PluginBuild) {
 }
 
 // registerTemplateHandler registers the template handler for Vue Single File Components.
-// Loads the precompiled template part and converts template compilation tips to build warnings.
-// Performs type-safe conversion of tips to prevent runtime panics.
+// Loads the precompiled template part and optionally attaches its sourcemap.
+// Template errors and tips are reported by the main entry handler with the other compiler diagnostics.
 func registerTemplateHandler(build *api.PluginBuild) {
 	build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: "sfc-template"}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
 		pluginData := args.PluginData.(map[string]interface{})
 
-		// Extract precompiled template result
-		templateResult := pluginData["template"].(map[string]interface{})
-		code := templateResult["code"].(string)
-
-		// Convert template compilation tips to build warnings with type safety
-		var mappedTips []api.Message
-		if tips, ok := templateResult["tips"].([]interface{}); ok {
-			for _, tip := range tips {
-				// Type-safe conversion to string to prevent panics from invalid tip types
-				if tipStr, isString := tip.(string); isString {
-					mappedTips = append(mappedTips, api.Message{
-						Text: tipStr,
-					})
-				}
-				// Silently skip non-string tips to maintain build stability
+		// Extract precompiled template result, inlined templates have no separate module
+		templateResult, _ := pluginData["template"].(map[string]interface{})
+		code, ok := templateResult["code"].(string)
+		if !ok {
+			return api.OnLoadResult{
+				Errors: []api.Message{{
+					Text: "Vue SFC has no separate template module, its template is missing or inlined into <script setup>",
+					Location: &api.Location{
+						File: args.Path,
+					},
+				}},
+			}, nil
+		}
+
+		// Map the render function back to the <template> block if sourcemaps are enabled and available
+		if build.InitialOptions.Sourcemap > 0 && templateResult["map"] != nil {
+			var err error
+			code, err = appendInlineSourceMap(code, templateResult["map"])
+			if err != nil {
+				return api.OnLoadResult{}, err
 			}
 		}
 
 		return api.OnLoadResult{
 			Contents:   &code,
-			Warnings:   mappedTips,
 			Loader:     api.LoaderTS,
 			ResolveDir: filepath.Dir(args.Path),
 			PluginData: pluginData,
@@ -430,9 +770,10 @@ func registerTemplateHandler(build *api.PluginBuild) {
 }
 
 // registerStyleHandler registers the style handler for Vue Single File Components.
-// Loads the precompiled style part based on the index specified in URL parameters.
+// Loads the precompiled style part based on the index specified in URL parameters,
+// runs the style processor chain and optionally attaches its sourcemap.
 // Supports multiple style blocks within a single Vue component.
-func registerStyleHandler(build *api.PluginBuild) {
+func registerStyleHandler(opts *Options, build *api.PluginBuild) {
 	build.OnLoad(api.OnLoadOptions{Filter: `.*`, Namespace: "sfc-style"}, func(args api.OnLoadArgs) (api.OnLoadResult, error) {
 		pluginData := args.PluginData.(map[string]interface{})
 
@@ -447,11 +788,61 @@ func registerStyleHandler(build *api.PluginBuild) {
 		styleResult := styles[index]
 		code := styleResult["code"].(string)
 
+		// Run the style processor chain, errors are reported at the <style> block position
+		meta := StyleMeta{File: strings.SplitN(args.Path, "?", 2)[0], Index: index, Lang: "css"}
+		meta.Scoped, _ = styleResult["scoped"].(bool)
+		if lang, ok := styleResult["lang"].(string); ok && lang != "" {
+			meta.Lang = lang
+		}
+
+		// The style sourcemap is chained through the processors so esbuild maps the CSS back to the <style> block
+		sourceMap := ""
+		if build.InitialOptions.Sourcemap > 0 && styleResult["map"] != nil && !params.Has("inline") {
+			encoded, err := json.Marshal(styleResult["map"])
+			if err != nil {
+				return api.OnLoadResult{}, err
+			}
+			sourceMap = string(encoded)
+		}
+		processed, err := applyStyleProcessors(opts, code, sourceMap, meta, build.InitialOptions)
+		if err != nil {
+			opts.logger.Error("Failed to process style block", "error", err, "file", meta.File)
+			return api.OnLoadResult{
+				Errors: []api.Message{{
+					Text: err.Error(),
+					Location: &api.Location{
+						File: meta.File,
+						Line: blockLine(styleResult),
+					},
+				}},
+			}, nil
+		}
+
+		// Styles of custom elements are imported as strings and attached to the component
+		if params.Has("inline") {
+			encoded, err := json.Marshal(processed.css)
+			if err != nil {
+				return api.OnLoadResult{}, err
+			}
+			contents := "export default " + string(encoded) + ";"
+			return api.OnLoadResult{
+				Contents:   &contents,
+				Loader:     api.LoaderJS,
+				ResolveDir: filepath.Dir(args.Path),
+			}, nil
+		}
+
+		code, err = processed.code()
+		if err != nil {
+			return api.OnLoadResult{}, err
+		}
+
 		return api.OnLoadResult{
 			Contents:   &code,
 			Loader:     api.LoaderCSS,
 			ResolveDir: filepath.Dir(args.Path),
 			PluginData: pluginData,
+			Warnings:   processed.warnings(&api.Location{File: meta.File, Line: blockLine(styleResult)}),
 		}, nil
 	})
 }
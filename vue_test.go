@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -128,11 +129,33 @@ func TestGenerateEntryContents(t *testing.T) {
 			styles:   []map[string]interface{}{},
 			expected: []string{"import { ssrRender } from 'test.vue?type=template'", "script.__ssrInlineRender = true;"},
 		},
+		{
+			name: "css_modules", filePath: "test.vue", dataId: "data-v-test", isSSR: false,
+			script:   map[string]interface{}{"content": "export default { name: 'ModuleComponent' }"},
+			template: map[string]interface{}{"code": "function render() { return h('div', 'test'); }"},
+			styles: []map[string]interface{}{
+				{"scoped": false, "module": "$style", "modules": map[string]interface{}{"red": "_red_1x2y3"}},
+				{"scoped": false, "module": "classes", "modules": nil},
+				{"scoped": false},
+			},
+			expected: []string{
+				"const cssModules = script.__cssModules = {};",
+				`cssModules["$style"] = {"red":"_red_1x2y3"};`,
+				`cssModules["classes"] = {};`,
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			contents, err := generateEntryContents(test.filePath, test.dataId, test.isSSR, test.script, test.template, test.styles)
+			contents, _, err := generateEntryContents(&entryOptions{
+				filePath: test.filePath,
+				dataId:   test.dataId,
+				isSSR:    test.isSSR,
+				script:   test.script,
+				template: test.template,
+				styles:   test.styles,
+			})
 			if err != nil {
 				t.Errorf("Expected no error, got: %v", err)
 			}
@@ -146,6 +169,155 @@ func TestGenerateEntryContents(t *testing.T) {
 	}
 }
 
+// TestGenerateEntryContentsWithHmr tests that components register themselves with the Vue HMR runtime
+func TestGenerateEntryContentsWithHmr(t *testing.T) {
+	script := map[string]interface{}{"content": "export default {}"}
+	contents, _, err := generateEntryContents(&entryOptions{
+		filePath: "test.vue",
+		dataId:   "data-v-test",
+		hmrId:    "7a7a37b1",
+		script:   script,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, expected := range []string{
+		"import * as __vue from 'vue';",
+		`script.__hmrId = "7a7a37b1";`,
+		"__VUE_HMR_RUNTIME__.createRecord(script.__hmrId, script);",
+	} {
+		if !strings.Contains(contents, expected) {
+			t.Errorf("Expected output to contain '%s', got:\n%s", expected, contents)
+		}
+	}
+
+	contents, _, _ = generateEntryContents(&entryOptions{
+		filePath: "test.vue",
+		dataId:   "data-v-test",
+		script:   script,
+	})
+	if strings.Contains(contents, "__hmrId") {
+		t.Errorf("Expected no HMR registration without HMR ID, got:\n%s", contents)
+	}
+}
+
+// TestGenerateEntryContentsSsrModules tests that server rendered components register their module ID
+func TestGenerateEntryContentsSsrModules(t *testing.T) {
+	script := map[string]interface{}{"content": "export default {}"}
+	contents, _, err := generateEntryContents(&entryOptions{
+		filePath:    "test.vue",
+		dataId:      "data-v-test",
+		ssrModuleId: "src/App.vue",
+		isSSR:       true,
+		script:      script,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, expected := range []string{
+		"import { useSSRContext as __useSSRContext } from 'vue';",
+		"const __setup = script.setup;",
+		`(ssrContext.modules || (ssrContext.modules = new Set())).add("src/App.vue");`,
+		"return __setup ? __setup(props, ctx) : undefined;",
+	} {
+		if !strings.Contains(contents, expected) {
+			t.Errorf("Expected output to contain '%s', got:\n%s", expected, contents)
+		}
+	}
+
+	contents, _, _ = generateEntryContents(&entryOptions{
+		filePath: "test.vue",
+		dataId:   "data-v-test",
+		isSSR:    true,
+		script:   script,
+	})
+	if strings.Contains(contents, "useSSRContext") {
+		t.Errorf("Expected no module registration without SSR module ID, got:\n%s", contents)
+	}
+}
+
+// TestGenerateEntryContentsInlineTemplate tests components whose template is inlined into <script setup>
+func TestGenerateEntryContentsInlineTemplate(t *testing.T) {
+	script := map[string]interface{}{"content": "export default { setup() { return () => null } }", "setup": true}
+	template := map[string]interface{}{"scoped": false}
+	for _, isSSR := range []bool{false, true} {
+		contents, _, err := generateEntryContents(&entryOptions{
+			filePath: "test.vue",
+			dataId:   "data-v-test",
+			isSSR:    isSSR,
+			script:   script,
+			template: template,
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if strings.Contains(contents, "?type=template") || strings.Contains(contents, "render") {
+			t.Errorf("Expected no template module, got:\n%s", contents)
+		}
+		if !strings.Contains(contents, "import script from 'test.vue?type=script'") {
+			t.Errorf("Expected the script module, got:\n%s", contents)
+		}
+	}
+}
+
+// TestGenerateEntryContentsCustomElement tests that custom elements import their styles as strings
+func TestGenerateEntryContentsCustomElement(t *testing.T) {
+	styles := []map[string]interface{}{{"scoped": false}, {"scoped": false}}
+	contents, _, err := generateEntryContents(&entryOptions{
+		filePath:        "test.ce.vue",
+		dataId:          "data-v-test",
+		isCustomElement: true,
+		script:          map[string]interface{}{"content": "export default {}"},
+		styles:          styles,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, expected := range []string{
+		"import style0 from 'test.ce.vue?type=style&index=0&inline'",
+		"import style1 from 'test.ce.vue?type=style&index=1&inline'",
+		"script.styles = [style0, style1];",
+	} {
+		if !strings.Contains(contents, expected) {
+			t.Errorf("Expected output to contain '%s', got:\n%s", expected, contents)
+		}
+	}
+	if strings.Contains(contents, "import 'test.ce.vue?type=style") {
+		t.Errorf("Expected no global style imports, got:\n%s", contents)
+	}
+}
+
+// TestGenerateEntryContentsWithoutCssModules tests that plain styles don't inject __cssModules
+func TestGenerateEntryContentsWithoutCssModules(t *testing.T) {
+	contents, _, err := generateEntryContents(&entryOptions{
+		filePath: "test.vue",
+		dataId:   "data-v-test",
+		script:   map[string]interface{}{"content": "export default {}"},
+		template: map[string]interface{}{"code": "render() {}"},
+		styles:   []map[string]interface{}{{"scoped": false}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if strings.Contains(contents, "__cssModules") {
+		t.Errorf("Expected no CSS modules injection, got:\n%s", contents)
+	}
+}
+
+// TestCollectCssModulesError tests that unencodable class mappings are reported
+func TestCollectCssModulesError(t *testing.T) {
+	_, _, err := generateEntryContents(&entryOptions{
+		filePath: "test.vue",
+		dataId:   "data-v-test",
+		script:   map[string]interface{}{"content": "export default {}"},
+		template: map[string]interface{}{"code": "render() {}"},
+		styles:   []map[string]interface{}{{"scoped": false, "module": "$style", "modules": make(chan int)}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to encode CSS modules") {
+		t.Errorf("Expected CSS modules encoding error, got: %v", err)
+	}
+}
+
 // TestGenerateEntryContentsErrors tests error conditions in generateEntryContents
 func TestGenerateEntryContentsErrors(t *testing.T) {
 	tests := []struct {
@@ -159,10 +331,13 @@ func TestGenerateEntryContentsErrors(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_, err := generateEntryContents("test.vue", "data-v-test", false,
-				map[string]interface{}{"content": "export default {}"},
-				map[string]interface{}{"code": "render() {}"},
-				test.styles)
+			_, _, err := generateEntryContents(&entryOptions{
+				filePath: "test.vue",
+				dataId:   "data-v-test",
+				script:   map[string]interface{}{"content": "export default {}"},
+				template: map[string]interface{}{"code": "render() {}"},
+				styles:   test.styles,
+			})
 
 			if err == nil {
 				t.Error("Expected error for invalid scoped value")
@@ -645,6 +820,70 @@ func TestVueResolveProcessorError(t *testing.T) {
 	})
 }
 
+// TestToStringSlice tests conversion of string lists decoded from the JS executor
+func TestToStringSlice(t *testing.T) {
+	result := toStringSlice([]interface{}{"/src/a.scss", 42, "/src/b.html"})
+	if len(result) != 2 || result[0] != "/src/a.scss" || result[1] != "/src/b.html" {
+		t.Errorf("Expected string entries only, got %v", result)
+	}
+	if result := toStringSlice("not-a-list"); result != nil {
+		t.Errorf("Expected nil for invalid input, got %v", result)
+	}
+}
+
+// TestVueBlockSrcOptions tests that path aliases are passed to the compiler and
+// dependencies of external block sources don't break the build
+func TestVueBlockSrcOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	vueFile := filepath.Join(tmpDir, "test.vue")
+	if err := os.WriteFile(vueFile, []byte(`<template src="@/views/test.html"></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './test.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	var compileOptions map[string]interface{}
+	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(&MockEngineConfig{
+		Template:     &MockTemplateConfig{Code: "export function render() { return 'ExternalTemplate'; }"},
+		Dependencies: []interface{}{filepath.Join(tmpDir, "views", "test.html")},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				compileOptions = req.Args[3].(map[string]interface{})
+			}
+		},
+	})))
+	if err != nil {
+		t.Fatalf("Failed to create JS executor: %v", err)
+	}
+	if err := jsExec.Start(); err != nil {
+		t.Fatalf("Failed to start JS executor: %v", err)
+	}
+	defer jsExec.Stop()
+
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelError,
+		AbsWorkingDir: tmpDir,
+		TsconfigRaw:   `{"compilerOptions": {"paths": {"@/*": ["./src/*"]}}}`,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+
+	pathAlias, ok := compileOptions["pathAlias"].(map[string]string)
+	if !ok || pathAlias["@/*"] != filepath.Join(tmpDir, "src", "*") {
+		t.Errorf("Expected path aliases to be passed to the compiler, got %v", compileOptions["pathAlias"])
+	}
+	if !strings.Contains(string(result.OutputFiles[0].Contents), "ExternalTemplate") {
+		t.Error("Expected output to contain the externally sourced template")
+	}
+}
+
 // TestVueScriptHandlerWithSourcemap tests script handler sourcemap functionality
 func TestVueScriptHandlerWithSourcemap(t *testing.T) {
 	tests := []struct {
@@ -791,137 +1030,216 @@ func filterVuePluginWarnings(warnings []api.Message) []api.Message {
 	return vueWarnings
 }
 
-// TestVueTemplateHandlerWithTips tests template handler tips functionality
-func TestVueTemplateHandlerWithTips(t *testing.T) {
-	tests := []struct {
-		name           string
-		tips           []interface{}
-		expectWarnings int
-	}{
-		{"with_tips", []interface{}{"Tip 1: Performance", "Tip 2: Accessibility", "Tip 3: Security"}, 3},
-		{"single_tip", []interface{}{"Single performance tip"}, 1},
-		{"empty_tips", []interface{}{}, 0},
-		{"nil_tips", nil, 0},
+// TestVueCustomElement tests that styles of custom elements are inlined instead of emitted as CSS
+func TestVueCustomElement(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"Widget.ce.vue"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(`<template><div>Test</div></template><style>.a {}</style>`), 0644); err != nil {
+			t.Fatalf("Failed to create Vue file: %v", err)
+		}
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import Widget from './Widget.ce.vue'; console.log(Widget.styles);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
 	}
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			mockConfig := &MockEngineConfig{
-				Script:   &MockScriptConfig{Content: "export default { name: 'TestComponent' }", Lang: "js"},
-				Template: &MockTemplateConfig{Code: "export function render() { return h('div', 'test'); }", Tips: test.tips},
+	jsExec := newMockExecutor(t, &MockEngineConfig{
+		Template: &MockTemplateConfig{Code: "export function render() { return null; }"},
+		Styles:   []*MockStyleConfig{{Code: ".widget { color: red; }", Scoped: false}},
+		OnRequest: func(req *jsexecutor.JsRequest) {
+			if req.Service == "sfc.vue.compileSFC" {
+				options := req.Args[3].(map[string]interface{})
+				if options["customElement"] != true {
+					t.Errorf("Expected custom element compilation, got %v", options["customElement"])
+				}
 			}
+		},
+	})
 
-			content := `<template><div>Test</div></template><script>export default { name: 'Test' }</script>`
-			tmpFile := createTempVueFile(t, content)
-			entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
-			entryContent := fmt.Sprintf(`import App from '%s';`, filepath.Base(tmpFile))
-			err := os.WriteFile(entryFile, []byte(entryContent), 0644)
-			if err != nil {
-				t.Fatalf("Failed to create entry file: %v", err)
-			}
-			defer os.Remove(entryFile)
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{entryFile},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelError,
+		AbsWorkingDir: tmpDir,
+		Outdir:        filepath.Join(tmpDir, "dist"),
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec), WithCustomElementPattern(regexp.MustCompile(`\.ce\.vue$`)))},
+	})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	for _, file := range result.OutputFiles {
+		if strings.HasSuffix(file.Path, ".css") {
+			t.Errorf("Expected no stylesheet for custom elements, got %s", file.Path)
+		}
+		if strings.HasSuffix(file.Path, ".js") && !strings.Contains(string(file.Contents), `".widget { color: red; }"`) {
+			t.Errorf("Expected inlined style string, got:\n%s", file.Contents)
+		}
+	}
+}
 
-			jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(mockConfig)))
-			if err != nil {
-				t.Fatalf("Failed to create JS executor: %v", err)
-			}
-			if err := jsExec.Start(); err != nil {
-				t.Fatalf("Failed to start JS executor: %v", err)
-			}
-			defer jsExec.Stop()
+// TestVueCustomElementWithCompiler tests that the compiler bundle inlines the styles of custom elements
+func TestVueCustomElementWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"Widget.ce.vue": "<template><div class=\"widget\">Widget</div></template>\n<style lang=\"scss\">\n$color: red;\n.widget { color: $color; }\n</style>",
+		"main.js":       `import Widget from './Widget.ce.vue'; console.log(Widget.styles);`,
+	})
 
-			vuePlugin := NewPlugin(WithJsExecutor(jsExec))
+	result := buildWithCompiler(t, tmpDir, "main.js", nil, WithCustomElementPattern(regexp.MustCompile(`\.ce\.vue$`)))
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	if css := outputFile(result, ".css"); css != "" {
+		t.Errorf("Expected no stylesheet for custom elements, got:\n%s", css)
+	}
+	if js := outputFile(result, ".js"); !regexp.MustCompile(`\.widget\s*\{\s*color:\s*red`).MatchString(js) || !strings.Contains(js, ".styles = [") {
+		t.Errorf("Expected the compiled style to be inlined into the component styles, got:\n%s", js)
+	}
+}
+
+// TestVueInlineTemplate tests that templates are inlined into <script setup> in production unless configured otherwise
+func TestVueInlineTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "App.vue"), []byte(`<script setup>const msg = 'Hello'</script><template><div>{{ msg }}</div></template>`), 0644); err != nil {
+		t.Fatalf("Failed to create Vue file: %v", err)
+	}
+	entryFile := filepath.Join(tmpDir, "entry.js")
+	if err := os.WriteFile(entryFile, []byte(`import App from './App.vue'; console.log(App);`), 0644); err != nil {
+		t.Fatalf("Failed to create entry file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		define   map[string]string
+		options  []OptionFunc
+		expected bool
+	}{
+		{"production", nil, nil, true},
+		{"development", map[string]string{"import.meta.env.PROD": "false"}, nil, false},
+		{"disabled", nil, []OptionFunc{WithInlineTemplate(false)}, false},
+		{"enabled_in_development", map[string]string{"import.meta.env.PROD": "false"}, []OptionFunc{WithInlineTemplate(true)}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var compileOptions map[string]interface{}
+			jsExec := newMockExecutor(t, &MockEngineConfig{
+				Script:   &MockScriptConfig{Content: "export default { setup() { return () => 'inlined' } }", Lang: "js", Setup: true},
+				Template: &MockTemplateConfig{Inline: test.expected},
+				OnRequest: func(req *jsexecutor.JsRequest) {
+					if req.Service == "sfc.vue.compileSFC" {
+						compileOptions = req.Args[3].(map[string]interface{})
+					}
+				},
+			})
 			result := api.Build(api.BuildOptions{
-				EntryPoints: []string{entryFile},
-				Bundle:      true,
-				Write:       false,
-				LogLevel:    api.LogLevelInfo,
-				Plugins:     []api.Plugin{vuePlugin},
+				EntryPoints:   []string{entryFile},
+				Bundle:        true,
+				Write:         false,
+				LogLevel:      api.LogLevelSilent,
+				AbsWorkingDir: tmpDir,
+				Define:        test.define,
+				Plugins:       []api.Plugin{NewPlugin(append([]OptionFunc{WithJsExecutor(jsExec)}, test.options...)...)},
 			})
-
 			if len(result.Errors) > 0 {
-				t.Errorf("Expected successful build, got errors: %v", result.Errors)
-				return
+				t.Fatalf("Expected successful build, got errors: %v", result.Errors)
 			}
-
-			vueWarnings := filterVuePluginWarnings(result.Warnings)
-			if len(vueWarnings) != test.expectWarnings {
-				t.Errorf("Expected %d Vue plugin warnings, got %d", test.expectWarnings, len(vueWarnings))
+			if compileOptions["inlineTemplate"] != test.expected {
+				t.Errorf("Expected inlineTemplate %t, got %v", test.expected, compileOptions["inlineTemplate"])
+			}
+			if test.expected && strings.Contains(string(result.OutputFiles[0].Contents), "type=template") {
+				t.Errorf("Expected no template module, got:\n%s", result.OutputFiles[0].Contents)
 			}
 		})
 	}
-}
-
-// TestVueTemplateHandlerWithInvalidTips tests invalid tips handling
-func TestVueTemplateHandlerWithInvalidTips(t *testing.T) {
-	// Test mixed valid and invalid tips - should only process valid string tips
-	invalidTips := []interface{}{"Valid tip", 42, "Another valid tip"} // 42 is invalid type
-
-	mockConfig := &MockEngineConfig{
-		Script:   &MockScriptConfig{Content: "export default { name: 'TestComponent' }", Lang: "js"},
-		Template: &MockTemplateConfig{Code: "export function render() { return h('div', 'test'); }", Tips: invalidTips},
-	}
 
-	content := `<template><div>Test</div></template><script>export default { name: 'Test' }</script>`
-	tmpFile := createTempVueFile(t, content)
-	entryFile := filepath.Join(filepath.Dir(tmpFile), "entry.js")
-	entryContent := fmt.Sprintf(`import App from '%s';`, filepath.Base(tmpFile))
-	err := os.WriteFile(entryFile, []byte(entryContent), 0644)
-	if err != nil {
+	// Importing the template module of a component without one fails with a build error
+	jsExec := newMockExecutor(t, &MockEngineConfig{Template: &MockTemplateConfig{Inline: true}})
+	templateEntry := filepath.Join(tmpDir, "template.js")
+	if err := os.WriteFile(templateEntry, []byte(`import { render } from './App.vue?type=template'; console.log(render);`), 0644); err != nil {
 		t.Fatalf("Failed to create entry file: %v", err)
 	}
-	defer os.Remove(entryFile)
+	result := api.Build(api.BuildOptions{
+		EntryPoints:   []string{templateEntry},
+		Bundle:        true,
+		Write:         false,
+		LogLevel:      api.LogLevelSilent,
+		AbsWorkingDir: tmpDir,
+		Plugins:       []api.Plugin{NewPlugin(WithJsExecutor(jsExec))},
+	})
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Text, "no separate template module") {
+		t.Errorf("Expected missing template module error, got: %v", result.Errors)
+	}
+}
 
-	jsExec, err := jsexecutor.NewExecutor(jsexecutor.WithJsEngine(NewMockEngineFactory(mockConfig)))
-	if err != nil {
-		t.Fatalf("Failed to create JS executor: %v", err)
+// TestVueInlineTemplateWithCompiler tests that the compiler bundle inlines templates into <script setup>
+func TestVueInlineTemplateWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue":  `<script setup>const msg = 'Hello'</script><template><div>{{ msg }}</div></template>`,
+		"entry.js": `import App from './App.vue'; console.log(App);`,
+	})
+
+	result := buildWithCompiler(t, tmpDir, "entry.js", nil)
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
 	}
-	if err := jsExec.Start(); err != nil {
-		t.Fatalf("Failed to start JS executor: %v", err)
+	js := outputFile(result, ".js")
+	if !strings.Contains(js, "Hello") || strings.Contains(js, "type=template") {
+		t.Errorf("Expected the template to be inlined into the component, got:\n%s", js)
 	}
-	defer jsExec.Stop()
+	// Inlined templates read the bindings of <script setup> directly instead of through $setup
+	if strings.Contains(js, "$setup") {
+		t.Errorf("Expected no render function with setup bindings, got:\n%s", js)
+	}
+}
 
-	vuePlugin := NewPlugin(WithJsExecutor(jsExec))
-	result := api.Build(api.BuildOptions{
-		EntryPoints: []string{entryFile},
-		Bundle:      true,
-		Write:       false,
-		LogLevel:    api.LogLevelInfo, // Change to Info to see warnings
-		Plugins:     []api.Plugin{vuePlugin},
+func TestVueCssModulesWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue": `<template><div :class="$style.red"></div></template>
+<style module>
+.red { color: red; }
+</style>`,
+		"main.js": `import App from './App.vue'; console.log(App);`,
 	})
 
-	// Should not have errors - invalid tips should be silently skipped
+	result := buildWithCompiler(t, tmpDir, "main.js", nil)
 	if len(result.Errors) > 0 {
-		t.Errorf("Expected successful build, got errors: %v", result.Errors)
-		return
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
 	}
 
-	// Should only have warnings for valid string tips (2 out of 3)
-	vueWarnings := filterVuePluginWarnings(result.Warnings)
-	expectedWarnings := 2 // Only "Valid tip" and "Another valid tip"
-	if len(vueWarnings) != expectedWarnings {
-		t.Errorf("Expected %d Vue plugin warnings (valid tips only), got %d", expectedWarnings, len(vueWarnings))
-		return
+	// The component gets the hashed class name, which is the one emitted in the CSS
+	match := regexp.MustCompile(`"red":\s*"([^"]+)"`).FindStringSubmatch(outputFile(result, ".js"))
+	if match == nil || match[1] == "red" {
+		t.Fatalf("Expected a hashed class mapping in the component, got:\n%s", outputFile(result, ".js"))
 	}
-
-	// Check that the valid tips are preserved (don't assume order)
-	expectedTips := map[string]bool{
-		"Valid tip":         false,
-		"Another valid tip": false,
+	if css := outputFile(result, ".css"); !strings.Contains(css, "."+match[1]) {
+		t.Errorf("Expected the CSS to use the hashed class %s, got:\n%s", match[1], css)
 	}
+}
 
-	for _, warning := range vueWarnings {
-		if _, exists := expectedTips[warning.Text]; exists {
-			expectedTips[warning.Text] = true
-		} else {
-			t.Errorf("Unexpected warning text: '%s'", warning.Text)
-		}
-	}
+func TestVueBlockSrcWithCompiler(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeProjectFiles(t, tmpDir, map[string]string{
+		"App.vue":        `<template src="./App.html"></template><script src="./app.js"></script><style src="./styles/app.css"></style>`,
+		"App.html":       `<div class="external-template">{{ name }}</div>`,
+		"app.js":         `export default { data: () => ({ name: 'external-script' }) }`,
+		"styles/app.css": `.external-template { color: red; }`,
+		"main.js":        `import App from './App.vue'; console.log(App);`,
+	})
 
-	// Verify all expected tips were found
-	for tip, found := range expectedTips {
-		if !found {
-			t.Errorf("Expected warning text '%s' not found", tip)
+	result := buildWithCompiler(t, tmpDir, "main.js", nil)
+	if len(result.Errors) > 0 {
+		t.Fatalf("Expected successful build, got errors: %v", result.Errors)
+	}
+	js := outputFile(result, ".js")
+	for _, expected := range []string{"external-template", "external-script"} {
+		if !strings.Contains(js, expected) {
+			t.Errorf("Expected the bundle to contain %s, got:\n%s", expected, js)
 		}
 	}
+	if css := outputFile(result, ".css"); !strings.Contains(css, "color: red") {
+		t.Errorf("Expected the external style in the CSS, got:\n%s", css)
+	}
 }